@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+
+	flag "github.com/spf13/pflag"
+)
+
+// flagGroups are flags that stand for one another, such as --json for
+// --format=json, or that cannot be combined, such as --template and
+// --format. Each group takes part in the precedence as a single flag.
+var flagGroups = [][]string{
+	{"color", "no-color"},
+	{"format", "json", "template", "template-file", "zero"},
+	{"indicator-style", "classify"},
+	{"type", "only-dirs", "only-files"},
+}
+
+// group returns the flags in the group of name, including name, or just
+// name if it is in none.
+func group(name string) []string {
+	for _, g := range flagGroups {
+		for _, member := range g {
+			if member == name {
+				return g
+			}
+		}
+	}
+	return []string{name}
+}
+
+// Apply fills in every flag of flags that was not given on the command
+// line from file, first from its top-level settings and then from the
+// named profile, if any, so that the precedence is built-in defaults,
+// config file, profile, command line.
+//
+// A setting is skipped when any flag of its group was given on the
+// command line, and it resets the other flags of its group that earlier
+// settings changed, so a profile's "format" beats a top-level "template".
+func Apply(flags *flag.FlagSet, file *File, profile string) error {
+	fromCommandLine := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		fromCommandLine[f.Name] = true
+	})
+
+	apply := func(settings []Setting) error {
+	settings:
+		for _, setting := range settings {
+			switch setting.Key {
+			case "config", "profile":
+				return fmt.Errorf("%s:%d: %q cannot be set here", file.Path, setting.Line, setting.Key)
+			}
+			if flags.Lookup(setting.Key) == nil {
+				return fmt.Errorf("%s:%d: unknown option %q", file.Path, setting.Line, setting.Key)
+			}
+			members := group(setting.Key)
+			for _, member := range members {
+				if fromCommandLine[member] {
+					continue settings
+				}
+			}
+			for _, member := range members {
+				if f := flags.Lookup(member); f != nil && member != setting.Key {
+					f.Value.Set(f.DefValue)
+				}
+			}
+			if err := flags.Set(setting.Key, setting.Value); err != nil {
+				return fmt.Errorf("%s:%d: %v", file.Path, setting.Line, err)
+			}
+		}
+		return nil
+	}
+
+	if err := apply(file.Settings); err != nil {
+		return err
+	}
+	if profile == "" {
+		return nil
+	}
+	settings, found := file.Profiles[profile]
+	if !found {
+		return fmt.Errorf("no profile %q in %s", profile, file.Path)
+	}
+	return apply(settings)
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	flag "github.com/spf13/pflag"
+)
+
+// newFlagSet returns a flag set with the flags the tests set, parsed from
+// args.
+func newFlagSet(t *testing.T, args ...string) *flag.FlagSet {
+	t.Helper()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("color", "auto", "")
+	flags.Bool("no-color", false, "")
+	flags.String("format", "", "")
+	flags.Bool("json", false, "")
+	flags.String("template", "", "")
+	flags.String("template-file", "", "")
+	flags.Bool("zero", false, "")
+	flags.String("type", "", "")
+	flags.Bool("only-dirs", false, "")
+	flags.Bool("only-files", false, "")
+	flags.Bool("dirsfirst", false, "")
+	flags.String("sort", "name", "")
+	flags.String("profile", "", "")
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags
+}
+
+func parse(t *testing.T, text string) *File {
+	t.Helper()
+	file, err := parseFile("config", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestApplyPrecedence(t *testing.T) {
+	const text = `
+sort = size
+dirsfirst = true
+template = "{{.Name}}"
+no-color = true
+type = l
+
+[profile p]
+sort = time
+only-dirs = true
+format = csv
+`
+	tests := []struct {
+		name    string
+		args    []string
+		profile string
+		want    map[string]string
+	}{
+		{
+			name: "config file over defaults",
+			want: map[string]string{"sort": "size", "dirsfirst": "true", "template": "{{.Name}}", "no-color": "true", "type": "l"},
+		},
+		{
+			name:    "profile over config file",
+			profile: "p",
+			want: map[string]string{
+				"sort": "time", "dirsfirst": "true",
+				// Each group takes the profile's setting alone.
+				"format": "csv", "template": "",
+				"only-dirs": "true", "type": "",
+			},
+		},
+		{
+			name:    "command line over profile",
+			args:    []string{"--sort=ext", "--dirsfirst=false"},
+			profile: "p",
+			want:    map[string]string{"sort": "ext", "dirsfirst": "false"},
+		},
+		{
+			name: "command line alias over config file",
+			args: []string{"--color=always"},
+			want: map[string]string{"color": "always", "no-color": "false"},
+		},
+		{
+			name: "command line over an exclusive flag in the config file",
+			args: []string{"--json"},
+			want: map[string]string{"json": "true", "template": "", "format": ""},
+		},
+		{
+			name:    "command line over an exclusive flag in the profile",
+			args:    []string{"--only-files"},
+			profile: "p",
+			want:    map[string]string{"only-files": "true", "only-dirs": "false", "type": "", "format": "csv"},
+		},
+		{
+			name:    "command line in one group leaves the others alone",
+			args:    []string{"--zero"},
+			profile: "p",
+			want:    map[string]string{"zero": "true", "format": "", "only-dirs": "true"},
+		},
+	}
+	for _, test := range tests {
+		flags := newFlagSet(t, test.args...)
+		if err := Apply(flags, parse(t, text), test.profile); err != nil {
+			t.Errorf("%s: Apply failed: %v", test.name, err)
+			continue
+		}
+		for name, want := range test.want {
+			if got := flags.Lookup(name).Value.String(); got != want {
+				t.Errorf("%s: --%s = %q, want %q", test.name, name, got, want)
+			}
+		}
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		text    string
+		profile string
+		err     string
+	}{
+		{"colour = never\n", "", `config:1: unknown option "colour"`},
+		{"\nprofile = p\n", "", `config:2: "profile" cannot be set here`},
+		{"[profile p]\nconfig = other\n", "p", `config:2: "config" cannot be set here`},
+		{"dirsfirst = maybe\n", "", "config:1: "},
+		{"sort = size\n", "missing", `no profile "missing" in config`},
+		// Profiles are only checked once selected.
+		{"[profile p]\nbogus = 1\n", "", ""},
+	}
+	for _, test := range tests {
+		err := Apply(newFlagSet(t), parse(t, test.text), test.profile)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("Apply(%q) failed: %v", test.text, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("Apply(%q) error = %v, want %q", test.text, err, test.err)
+		}
+	}
+}
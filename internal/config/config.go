@@ -1,10 +1,33 @@
 package config
 
+import "github.com/SiirRandall/lsd-go/internal/filter"
+
 type Config struct {
 	SortAlphabetical bool
+	SortKeys         []string
 	SortReverse      bool
 	DirsFirst        bool
 	ShowDotFiles     bool
+	ShowDotEntries   bool
+	Ignore           []filter.Pattern
+	Match            []filter.Pattern
+	GitIgnore        *filter.GitIgnore // hides the entries git ignores, if set
+	Types            string            // letters of the entry types to list, all if empty
+	Where            *filter.Expr      // lists only the entries it matches, if set
+	Listed           map[string]bool   // whether the tree lists anything in each directory, filled in as it is walked
+	ShowInodes       bool
+	Headers          bool
+	NoColor          bool
+	Format           string
+	Template         string
+	MarkdownIcons    bool
+	OnePerLine       bool
+	NullTerminate    bool
+	Hyperlinks       bool
+	IndicatorStyle   string
+	ShowKind         bool
+	Tree             bool
 	Dir              string
+	Args             []string
 	MaxDepth         int
 }
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Setting is a single "key = value" line of a configuration file. Keys are
+// the long names of the command-line flags.
+type Setting struct {
+	Key   string
+	Value string
+	Line  int
+}
+
+// File is a parsed configuration file. Settings outside of any section
+// apply to every invocation; settings under a "[profile NAME]" header only
+// apply when that profile is selected with --profile.
+//
+//	# ~/.config/lsd-go/config
+//	dirsfirst = true
+//	hyperlink = auto
+//
+//	[profile review]
+//	list = true
+//	headers = true
+type File struct {
+	Path     string
+	Settings []Setting
+	Profiles map[string][]Setting
+}
+
+// FilePath returns the default location of the configuration file,
+// $XDG_CONFIG_HOME/lsd-go/config, falling back to ~/.config/lsd-go/config.
+func FilePath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "lsd-go", "config")
+}
+
+// LoadFile reads the configuration file at path. Unless required is set, a
+// missing file is not an error and yields an empty configuration.
+func LoadFile(path string, required bool) (*File, error) {
+	file := &File{Path: path, Profiles: map[string][]Setting{}}
+	if path == "" {
+		return file, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseFile(path, f)
+}
+
+// parseFile parses the configuration file at path, whose content is read
+// from r.
+func parseFile(path string, r io.Reader) (*File, error) {
+	file := &File{Path: path, Profiles: map[string][]Setting{}}
+	profile := ""
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			fields := strings.Fields(line[1 : len(line)-1])
+			if len(fields) != 2 || fields[0] != "profile" {
+				return nil, fmt.Errorf("%s:%d: expected a [profile NAME] header", path, lineNum)
+			}
+			profile = fields[1]
+			if _, exists := file.Profiles[profile]; exists {
+				return nil, fmt.Errorf("%s:%d: profile %q is defined twice", path, lineNum, profile)
+			}
+			file.Profiles[profile] = nil
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, lineNum)
+		}
+		setting := Setting{
+			Key:   strings.TrimSpace(key),
+			Value: unquote(strings.TrimSpace(value)),
+			Line:  lineNum,
+		}
+		if profile == "" {
+			file.Settings = append(file.Settings, setting)
+		} else {
+			file.Profiles[profile] = append(file.Profiles[profile], setting)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// unquote strips one pair of matching double or single quotes, so values
+// such as templates can keep leading or trailing spaces.
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if first == last && (first == '"' || first == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	file := parse(t, `
+# comment
+dirsfirst = true
+  template = "{{.Name}} "
+theme='light'
+empty =
+
+[profile review]
+list = true
+# another comment
+  [ profile  wide ]
+headers = true
+`)
+	wantSettings := []Setting{
+		{Key: "dirsfirst", Value: "true", Line: 3},
+		{Key: "template", Value: "{{.Name}} ", Line: 4},
+		{Key: "theme", Value: "light", Line: 5},
+		{Key: "empty", Value: "", Line: 6},
+	}
+	if !reflect.DeepEqual(file.Settings, wantSettings) {
+		t.Errorf("Settings = %+v, want %+v", file.Settings, wantSettings)
+	}
+	wantProfiles := map[string][]Setting{
+		"review": {{Key: "list", Value: "true", Line: 9}},
+		"wide":   {{Key: "headers", Value: "true", Line: 12}},
+	}
+	if !reflect.DeepEqual(file.Profiles, wantProfiles) {
+		t.Errorf("Profiles = %+v, want %+v", file.Profiles, wantProfiles)
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{"dirsfirst\n", "config:1: expected key = value"},
+		{"[review]\n", "config:1: expected a [profile NAME] header"},
+		{"[profile]\n", "config:1: expected a [profile NAME] header"},
+		{"[profile a]\n[profile b]\n[profile a]\n", `config:3: profile "a" is defined twice`},
+	}
+	for _, test := range tests {
+		_, err := parseFile("config", strings.NewReader(test.text))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("parseFile(%q) error = %v, want %q", test.text, err, test.err)
+		}
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	file, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatalf("LoadFile of a missing default file failed: %v", err)
+	}
+	if len(file.Settings) != 0 || len(file.Profiles) != 0 {
+		t.Errorf("LoadFile of a missing file = %+v, want an empty configuration", file)
+	}
+
+	if _, err := LoadFile(path, true); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadFile of a missing required file error = %v, want os.ErrNotExist", err)
+	}
+
+	if err := os.WriteFile(path, []byte("sort = size\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err = LoadFile(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Settings) != 1 || file.Path != path {
+		t.Errorf("LoadFile = %+v, want one setting from %s", file, path)
+	}
+}
@@ -0,0 +1,580 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Expr is a compiled --where expression, a predicate over the metadata of
+// an entry such as
+//
+//	size > 10MB && mtime < 7d && ext in ["log", "gz"] && !hidden
+//
+// Expressions combine comparisons with &&, || and !, and parentheses.
+// Comparisons are ==, !=, <, <=, >, >=, =~ and !~ (regular expression
+// match) and in (membership in a list literal). Sizes take the binary
+// units B, K, KB, KiB, M, MB, MiB, G, GB, GiB, T, TB and TiB, and
+// durations the units s, min, h, d, w and y. The fields are listed in
+// fields.go.
+type Expr struct {
+	root node
+	now  time.Time // what ages are measured from
+}
+
+// SyntaxError is a mistake in an expression, at a 1-based column.
+type SyntaxError struct {
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+// ParseExpr compiles text, checking the fields it names and the types of
+// the values it compares.
+func ParseExpr(text string) (*Expr, error) {
+	tokens, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+	if root.typ() != typeBool {
+		return nil, &SyntaxError{Column: 1, Msg: fmt.Sprintf("expression is a %s, not a condition", root.typ())}
+	}
+	return &Expr{root: root, now: time.Now()}, nil
+}
+
+// Match reports whether the entry at path, whose Lstat result is info,
+// satisfies e.
+func (e *Expr) Match(path string, info os.FileInfo) bool {
+	return e.root.eval(&entry{path: path, info: info, now: e.now}).b
+}
+
+// valueType is the static type of a node.
+type valueType int
+
+const (
+	typeBool valueType = iota
+	typeString
+	typeNumber
+	typeSize
+	typeDuration
+)
+
+func (t valueType) String() string {
+	return [...]string{"condition", "string", "number", "size", "duration"}[t]
+}
+
+// comparable reports whether values of types a and b can be compared.
+// Plain numbers compare with sizes, as bytes, and with durations, as
+// seconds.
+func comparable(a, b valueType) bool {
+	if a == b {
+		return true
+	}
+	return (a == typeNumber && (b == typeSize || b == typeDuration)) ||
+		(b == typeNumber && (a == typeSize || a == typeDuration))
+}
+
+func isNumeric(t valueType) bool {
+	return t == typeNumber || t == typeSize || t == typeDuration
+}
+
+type value struct {
+	b bool
+	n float64
+	s string
+}
+
+type node interface {
+	typ() valueType
+	eval(e *entry) value
+}
+
+type literal struct {
+	t valueType
+	v value
+}
+
+func (l *literal) typ() valueType    { return l.t }
+func (l *literal) eval(*entry) value { return l.v }
+
+type fieldNode struct {
+	field field
+}
+
+func (f *fieldNode) typ() valueType      { return f.field.t }
+func (f *fieldNode) eval(e *entry) value { return f.field.get(e) }
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) typ() valueType { return typeBool }
+func (n *notNode) eval(e *entry) value {
+	return value{b: !n.operand.eval(e).b}
+}
+
+type logicNode struct {
+	and         bool
+	left, right node
+}
+
+func (n *logicNode) typ() valueType { return typeBool }
+func (n *logicNode) eval(e *entry) value {
+	left := n.left.eval(e).b
+	if n.and != left {
+		return value{b: left}
+	}
+	return n.right.eval(e)
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) typ() valueType { return typeBool }
+func (n *compareNode) eval(e *entry) value {
+	left, right := n.left.eval(e), n.right.eval(e)
+	var c int
+	switch {
+	case isNumeric(n.left.typ()):
+		c = compareFloats(left.n, right.n)
+	case n.left.typ() == typeString:
+		c = strings.Compare(left.s, right.s)
+	default:
+		c = compareFloats(boolNumber(left.b), boolNumber(right.b))
+	}
+	switch n.op {
+	case "==":
+		return value{b: c == 0}
+	case "!=":
+		return value{b: c != 0}
+	case "<":
+		return value{b: c < 0}
+	case "<=":
+		return value{b: c <= 0}
+	case ">":
+		return value{b: c > 0}
+	default:
+		return value{b: c >= 0}
+	}
+}
+
+type matchNode struct {
+	negate  bool
+	operand node
+	re      *regexp.Regexp
+}
+
+func (n *matchNode) typ() valueType { return typeBool }
+func (n *matchNode) eval(e *entry) value {
+	return value{b: n.re.MatchString(n.operand.eval(e).s) != n.negate}
+}
+
+type inNode struct {
+	operand node
+	list    []node
+}
+
+func (n *inNode) typ() valueType { return typeBool }
+func (n *inNode) eval(e *entry) value {
+	for _, item := range n.list {
+		eq := compareNode{op: "==", left: n.operand, right: item}
+		if eq.eval(e).b {
+			return value{b: true}
+		}
+	}
+	return value{b: false}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolNumber(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind   tokenKind
+	text   string // as written
+	column int
+	str    string    // value of a string
+	num    float64   // value of a number
+	unit   valueType // type of a number, given by its unit
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+var sizeUnits = map[string]float64{
+	"b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+var durationUnits = map[string]float64{
+	"s":   1,
+	"min": 60,
+	"h":   60 * 60,
+	"d":   24 * 60 * 60,
+	"w":   7 * 24 * 60 * 60,
+	"y":   365 * 24 * 60 * 60,
+}
+
+// operators lists the operators, longest first so that "<=" is not read
+// as "<".
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")", "[", "]", ","}
+
+func lex(text string) ([]token, error) {
+	runes := []rune(text)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, &SyntaxError{Column: column, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, token{kind: tokString, text: string(runes[i : j+1]), column: column, str: b.String()})
+			i = j + 1
+
+		case r >= '0' && r <= '9':
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || unicode.IsLetter(runes[j]) || runes[j] == '.' || runes[j] == '_') {
+				j++
+			}
+			tok, err := lexNumber(string(runes[i:j]), column)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = j
+
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[i:j]), column: column})
+			i = j
+
+		default:
+			rest := string(runes[i:])
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(rest, candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &SyntaxError{Column: column, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, column: column})
+			i += len([]rune(op))
+		}
+	}
+	return append(tokens, token{kind: tokEOF, column: len(runes) + 1}), nil
+}
+
+// lexNumber parses a number with an optional unit, such as "10", "0755",
+// "1.5GB" or "7d". Integers with a leading zero are octal, like file
+// modes.
+func lexNumber(text string, column int) (token, error) {
+	tok := token{kind: tokNumber, text: text, column: column, unit: typeNumber}
+	digits := strings.TrimRightFunc(text, unicode.IsLetter)
+	unit := strings.ToLower(text[len(digits):])
+
+	var err error
+	if len(digits) > 1 && digits[0] == '0' && !strings.Contains(digits, ".") && unit == "" {
+		var n int64
+		n, err = strconv.ParseInt(digits[1:], 8, 64)
+		tok.num = float64(n)
+	} else {
+		tok.num, err = strconv.ParseFloat(digits, 64)
+	}
+	if err != nil || math.IsInf(tok.num, 0) {
+		return tok, &SyntaxError{Column: column, Msg: fmt.Sprintf("invalid number %q", text)}
+	}
+
+	switch {
+	case unit == "":
+	case sizeUnits[unit] != 0:
+		tok.num *= sizeUnits[unit]
+		tok.unit = typeSize
+	case durationUnits[unit] != 0:
+		tok.num *= durationUnits[unit]
+		tok.unit = typeDuration
+	default:
+		return tok, &SyntaxError{Column: column + len(digits), Msg: fmt.Sprintf("unknown unit %q", text[len(digits):])}
+	}
+	return tok, nil
+}
+
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the operator or keyword text.
+func (p *parser) accept(text string) bool {
+	tok := p.peek()
+	if (tok.kind == tokOp || tok.kind == tokIdent) && tok.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &SyntaxError{Column: tok.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expectBool(tok token, n node, op string) error {
+	if n.typ() != typeBool {
+		return p.errorf(tok, "%s needs a condition, not a %s", op, n.typ())
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	first := p.peek()
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		rightTok := p.peek()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectBool(first, left, "||"); err != nil {
+			return nil, err
+		}
+		if err := p.expectBool(rightTok, right, "||"); err != nil {
+			return nil, err
+		}
+		left = &logicNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	first := p.peek()
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		rightTok := p.peek()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectBool(first, left, "&&"); err != nil {
+			return nil, err
+		}
+		if err := p.expectBool(rightTok, right, "&&"); err != nil {
+			return nil, err
+		}
+		left = &logicNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		tok := p.peek()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectBool(tok, operand, "!"); err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	leftTok := p.peek()
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.peek()
+	switch {
+	case op.kind == tokOp && (op.text == "=~" || op.text == "!~"):
+		p.next()
+		pattern := p.next()
+		if pattern.kind != tokString {
+			return nil, p.errorf(pattern, "%s needs a regular expression in quotes, not %s", op.text, pattern)
+		}
+		re, err := regexp.Compile(pattern.str)
+		if err != nil {
+			return nil, p.errorf(pattern, "invalid regular expression: %v", err)
+		}
+		if left.typ() != typeString {
+			return nil, p.errorf(leftTok, "%s needs a string, not a %s", op.text, left.typ())
+		}
+		return &matchNode{negate: op.text == "!~", operand: left, re: re}, nil
+
+	case op.kind == tokIdent && op.text == "in":
+		p.next()
+		list, err := p.parseList(left.typ())
+		if err != nil {
+			return nil, err
+		}
+		return &inNode{operand: left, list: list}, nil
+
+	case op.kind == tokOp && comparisons[op.text]:
+		p.next()
+		rightTok := p.peek()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !comparable(left.typ(), right.typ()) {
+			return nil, p.errorf(rightTok, "cannot compare a %s with a %s", left.typ(), right.typ())
+		}
+		if left.typ() == typeBool && op.text != "==" && op.text != "!=" {
+			return nil, p.errorf(op, "conditions can only be compared with == and !=")
+		}
+		return &compareNode{op: op.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseList(elem valueType) ([]node, error) {
+	open := p.next()
+	if open.kind != tokOp || open.text != "[" {
+		return nil, p.errorf(open, "in needs a list such as [\"a\", \"b\"], not %s", open)
+	}
+	var list []node
+	for {
+		tok := p.peek()
+		if len(list) == 0 && p.accept("]") {
+			return list, nil
+		}
+		item, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := item.(*literal); !ok {
+			return nil, p.errorf(tok, "lists can only hold literal values")
+		}
+		if !comparable(elem, item.typ()) {
+			return nil, p.errorf(tok, "cannot compare a %s with a %s", elem, item.typ())
+		}
+		list = append(list, item)
+		if p.accept("]") {
+			return list, nil
+		}
+		if !p.accept(",") {
+			return nil, p.errorf(p.peek(), "expected \",\" or \"]\", found %s", p.peek())
+		}
+	}
+}
+
+func (p *parser) parseOperand() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokString:
+		return &literal{t: typeString, v: value{s: tok.str}}, nil
+	case tokNumber:
+		return &literal{t: tok.unit, v: value{n: tok.num}}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &literal{t: typeBool, v: value{b: tok.text == "true"}}, nil
+		}
+		f, ok := fields[tok.text]
+		if !ok {
+			return nil, p.errorf(tok, "unknown field %q (known fields: %s)", tok.text, strings.Join(fieldNames(), ", "))
+		}
+		return &fieldNode{field: f}, nil
+	case tokOp:
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if closing := p.next(); closing.kind != tokOp || closing.text != ")" {
+				return nil, p.errorf(closing, "expected \")\", found %s", closing)
+			}
+			return inner, nil
+		}
+	}
+	return nil, p.errorf(tok, "unexpected %s", tok)
+}
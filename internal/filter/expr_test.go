@@ -0,0 +1,196 @@
+package filter
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeInfo is a synthetic os.FileInfo.
+type fakeInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+	sys     interface{}
+}
+
+func (f fakeInfo) Name() string       { return f.name }
+func (f fakeInfo) Size() int64        { return f.size }
+func (f fakeInfo) Mode() os.FileMode  { return f.mode }
+func (f fakeInfo) ModTime() time.Time { return f.modTime }
+func (f fakeInfo) IsDir() bool        { return f.mode.IsDir() }
+func (f fakeInfo) Sys() interface{}   { return f.sys }
+
+func TestLexNumber(t *testing.T) {
+	tests := []struct {
+		text string
+		num  float64
+		unit valueType
+	}{
+		{"10", 10, typeNumber},
+		{"0", 0, typeNumber},
+		{"1.5", 1.5, typeNumber},
+		{"0.5", 0.5, typeNumber},
+		// A leading zero makes an integer octal, as for file modes.
+		{"0644", 0644, typeNumber},
+		{"644", 644, typeNumber},
+		{"04755", 04755, typeNumber},
+		// Sizes are binary, and units are case-insensitive, so "1k" and
+		// "1K" are both KiB.
+		{"10B", 10, typeSize},
+		{"1k", 1 << 10, typeSize},
+		{"1K", 1 << 10, typeSize},
+		{"1kb", 1 << 10, typeSize},
+		{"1KiB", 1 << 10, typeSize},
+		{"2M", 2 << 20, typeSize},
+		{"1m", 1 << 20, typeSize}, // "m" is a size; minutes are "min"
+		{"1.5GB", 1.5 * (1 << 30), typeSize},
+		{"1TiB", 1 << 40, typeSize},
+		{"010k", 10 << 10, typeSize}, // not octal with a unit
+		{"30s", 30, typeDuration},
+		{"2min", 120, typeDuration},
+		{"2MIN", 120, typeDuration},
+		{"1h", 3600, typeDuration},
+		{"7d", 7 * 24 * 3600, typeDuration},
+		{"1w", 7 * 24 * 3600, typeDuration},
+		{"1y", 365 * 24 * 3600, typeDuration},
+	}
+	for _, test := range tests {
+		tok, err := lexNumber(test.text, 1)
+		if err != nil {
+			t.Errorf("lexNumber(%q) failed: %v", test.text, err)
+			continue
+		}
+		if tok.num != test.num || tok.unit != test.unit {
+			t.Errorf("lexNumber(%q) = %v %s, want %v %s", test.text, tok.num, tok.unit, test.num, test.unit)
+		}
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	tests := []struct {
+		text   string
+		column int
+		msg    string
+	}{
+		{"size >", 7, "unexpected end of expression"},
+		{"size > 1 &&", 12, "unexpected end of expression"},
+		{"sise > 1", 1, `unknown field "sise"`},
+		{"size > 10XB", 10, `unknown unit "XB"`},
+		{"mtime < 1h30m", 9, `invalid number "1h30m"`}, // units cannot be combined
+		{"mode == 0648", 9, `invalid number "0648"`},   // 8 is not an octal digit
+		{"size > 1.2.3", 8, `invalid number "1.2.3"`},
+		{`name == "abc`, 9, "unterminated string"},
+		{"size > 1 $", 10, `unexpected character '$'`},
+		{"uid == -1", 8, `unexpected character '-'`}, // no negative literals
+		{"size", 1, "expression is a size, not a condition"},
+		{"name > 1", 8, "cannot compare a string with a number"},
+		{"size > 1d", 8, "cannot compare a size with a duration"},
+		{"hidden < true", 8, "conditions can only be compared with == and !="},
+		{`name =~ "("`, 9, "invalid regular expression"},
+		{"name =~ x", 9, "needs a regular expression in quotes"},
+		{"size =~ \"1\"", 1, "=~ needs a string, not a size"},
+		{`ext in "go"`, 8, "in needs a list"},
+		{"ext in [name]", 9, "lists can only hold literal values"},
+		{`ext in ["a" "b"]`, 13, `expected "," or "]"`},
+		{"ext in [1]", 9, "cannot compare a string with a number"},
+		{"size > 1 || name", 13, "|| needs a condition, not a string"},
+		{"name && hidden", 1, "&& needs a condition, not a string"},
+		{"!size", 2, "! needs a condition, not a size"},
+		{"(size > 1", 10, `expected ")"`},
+		{"size > 1)", 9, `unexpected ")"`},
+	}
+	for _, test := range tests {
+		_, err := ParseExpr(test.text)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("ParseExpr(%q) error = %v, want a SyntaxError", test.text, err)
+			continue
+		}
+		if syntaxErr.Column != test.column || !strings.Contains(syntaxErr.Msg, test.msg) {
+			t.Errorf("ParseExpr(%q) error = %v, want column %d: %s", test.text, err, test.column, test.msg)
+		}
+	}
+}
+
+func TestExprMatch(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	file := fakeInfo{
+		name:    "app.LOG",
+		size:    20 << 20,
+		mode:    0644,
+		modTime: now.Add(-3 * 24 * time.Hour),
+	}
+	script := fakeInfo{name: "run.sh", size: 1024, mode: 0755, modTime: now.Add(-30 * 24 * time.Hour)}
+	setuid := fakeInfo{name: "su", mode: 0755 | os.ModeSetuid, modTime: now}
+	dotfile := fakeInfo{name: ".bashrc", size: 100, mode: 0600, modTime: now.Add(-time.Hour)}
+	dir := fakeInfo{name: "src", mode: os.ModeDir | 0755, modTime: now}
+
+	tests := []struct {
+		text string
+		info fakeInfo
+		want bool
+	}{
+		{"size > 10MB", file, true},
+		{"size > 10MB", script, false},
+		{"size >= 1k && size <= 1KiB", script, true},
+		{"size == 1024", script, true},
+		{"mtime < 7d", file, true},
+		{"mtime < 7d", script, false},
+		{"mtime > 4w", script, true},
+		{"mtime <= 1h", dotfile, true},
+		{"atime < 2h", dotfile, true}, // the modification time without stat data
+
+		// Modes written with a leading zero are octal.
+		{"mode == 0644", file, true},
+		{"mode == 644", file, false},
+		{"mode == 420", file, true},
+		{"mode == 04755", setuid, true},
+		{`perms == "rw-r--r--"`, file, true},
+		{"executable", script, true},
+		{"executable", file, false},
+		{"executable", dir, false},
+
+		{`ext == "log"`, file, true}, // lower-cased
+		{`ext == ""`, dotfile, true},
+		{`ext in ["log", "gz"]`, file, true},
+		{`ext in ["gz"]`, file, false},
+		{"ext in []", file, false},
+		{"size in [1, 1k, 2k]", script, true},
+		{"size in [1, 2k]", script, false},
+		{`name =~ "^app\\."`, file, true},
+		{`name !~ "^app"`, file, false},
+		{`type == "f"`, file, true},
+		{`type == "d"`, dir, true},
+		{`type in ["d", "l"]`, file, false},
+		{"hidden", dotfile, true},
+		{"hidden == false", file, true},
+		{"!hidden && !executable", file, true},
+
+		{"uid < 0 && gid < 0", script, true}, // no stat data
+		{"inode == 0", script, true},
+		{`user == "" && group == ""`, script, true},
+
+		// && binds tighter than ||, and ! tighter than both.
+		{"false || true && false", file, false},
+		{"true || true && false", file, true},
+		{"(false || true) && true", file, true},
+		{"!false && false", file, false},
+		{"!(false && false)", file, true},
+		{"hidden || size > 10MB && mtime < 1d", file, false},
+	}
+	for _, test := range tests {
+		expr, err := ParseExpr(test.text)
+		if err != nil {
+			t.Errorf("ParseExpr(%q) failed: %v", test.text, err)
+			continue
+		}
+		expr.now = now
+		if got := expr.Match(test.info.name, test.info); got != test.want {
+			t.Errorf("%q on %s = %v, want %v", test.text, test.info.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package filter
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExprMatchStat(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	file := fakeInfo{
+		name:    "app.log",
+		mode:    0644,
+		modTime: now,
+		sys:     &syscall.Stat_t{Uid: 1000, Gid: 100, Ino: 42},
+	}
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"uid == 1000 && gid == 100", true},
+		{"uid == 0", false},
+		{"inode == 42", true},
+		{`user != ""`, true},
+		{`group != ""`, true},
+	}
+	for _, test := range tests {
+		expr, err := ParseExpr(test.text)
+		if err != nil {
+			t.Errorf("ParseExpr(%q) failed: %v", test.text, err)
+			continue
+		}
+		expr.now = now
+		if got := expr.Match(file.name, file); got != test.want {
+			t.Errorf("%q on %s = %v, want %v", test.text, file.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package filter
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entry is what an expression is evaluated against.
+type entry struct {
+	path string
+	info os.FileInfo
+	now  time.Time
+}
+
+func (e *entry) age(t time.Time) value {
+	return value{n: e.now.Sub(t).Seconds()}
+}
+
+type field struct {
+	t   valueType
+	get func(e *entry) value
+}
+
+// fields are the entry metadata expressions can use. Times are ages, so
+// "mtime < 7d" means modified within the last week.
+var fields = map[string]field{
+	"name": {typeString, func(e *entry) value { return value{s: e.info.Name()} }},
+	"path": {typeString, func(e *entry) value { return value{s: e.path} }},
+	"ext": {typeString, func(e *entry) value {
+		return value{s: strings.ToLower(strings.TrimPrefix(filepath.Ext(strings.TrimPrefix(e.info.Name(), ".")), "."))}
+	}},
+	"type":  {typeString, func(e *entry) value { return value{s: string(TypeLetter(e.info.Mode()))} }},
+	"size":  {typeSize, func(e *entry) value { return value{n: float64(e.info.Size())} }},
+	"mtime": {typeDuration, func(e *entry) value { return e.age(e.info.ModTime()) }},
+	"atime": {typeDuration, func(e *entry) value { return e.age(AccessTime(e.info)) }},
+	"ctime": {typeDuration, func(e *entry) value { return e.age(ChangeTime(e.info)) }},
+	"user":  {typeString, func(e *entry) value { return value{s: userName(e)} }},
+	"group": {typeString, func(e *entry) value { return value{s: groupName(e)} }},
+	"uid": {typeNumber, func(e *entry) value {
+		if uid, _, ok := owner(e.info); ok {
+			return value{n: float64(uid)}
+		}
+		return value{n: -1}
+	}},
+	"gid": {typeNumber, func(e *entry) value {
+		if _, gid, ok := owner(e.info); ok {
+			return value{n: float64(gid)}
+		}
+		return value{n: -1}
+	}},
+	"inode": {typeNumber, func(e *entry) value {
+		if ino, ok := inode(e.info); ok {
+			return value{n: float64(ino)}
+		}
+		return value{}
+	}},
+	"mode":  {typeNumber, func(e *entry) value { return value{n: float64(unixMode(e.info.Mode()))} }},
+	"perms": {typeString, func(e *entry) value { return value{s: e.info.Mode().Perm().String()[1:]} }},
+	"hidden": {typeBool, func(e *entry) value {
+		return value{b: strings.HasPrefix(e.info.Name(), ".")}
+	}},
+	"executable": {typeBool, func(e *entry) value {
+		return value{b: e.info.Mode().IsRegular() && e.info.Mode().Perm()&0111 != 0}
+	}},
+}
+
+func fieldNames() []string {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unixMode returns the permission bits of mode as chmod takes them,
+// including the setuid, setgid and sticky bits.
+func unixMode(mode os.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		bits |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		bits |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		bits |= 01000
+	}
+	return bits
+}
+
+var (
+	userNames  = map[uint32]string{}
+	groupNames = map[uint32]string{}
+)
+
+// userName returns the name of the owner of e, or its uid if it has none.
+func userName(e *entry) string {
+	uid, _, ok := owner(e.info)
+	if !ok {
+		return ""
+	}
+	name, ok := userNames[uid]
+	if !ok {
+		id := strconv.FormatUint(uint64(uid), 10)
+		name = id
+		if u, err := user.LookupId(id); err == nil {
+			name = u.Username
+		}
+		userNames[uid] = name
+	}
+	return name
+}
+
+// groupName returns the name of the group of e, or its gid if it has none.
+func groupName(e *entry) string {
+	_, gid, ok := owner(e.info)
+	if !ok {
+		return ""
+	}
+	name, ok := groupNames[gid]
+	if !ok {
+		id := strconv.FormatUint(uint64(gid), 10)
+		name = id
+		if g, err := user.LookupGroupId(id); err == nil {
+			name = g.Name
+		}
+		groupNames[gid] = name
+	}
+	return name
+}
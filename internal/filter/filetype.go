@@ -0,0 +1,52 @@
+package filter
+
+import (
+	"os"
+	"time"
+)
+
+// TypeLetters are the letters --type accepts, one for each type of entry
+// TypeLetter tells apart.
+const TypeLetters = "fdlpsbc"
+
+// TypeLetter returns the letter find -type uses for mode: f for regular
+// files, d for directories, l for symlinks, p for named pipes, s for
+// sockets, b for block devices and c for character devices. Other types
+// have none.
+func TypeLetter(mode os.FileMode) byte {
+	switch {
+	case mode&os.ModeSymlink != 0:
+		return 'l'
+	case mode.IsDir():
+		return 'd'
+	case mode&os.ModeNamedPipe != 0:
+		return 'p'
+	case mode&os.ModeSocket != 0:
+		return 's'
+	case mode&os.ModeCharDevice != 0:
+		return 'c'
+	case mode&os.ModeDevice != 0:
+		return 'b'
+	case mode.IsRegular():
+		return 'f'
+	}
+	return 0
+}
+
+// AccessTime returns when the file described by info was last read, or
+// its modification time where that is not known.
+func AccessTime(info os.FileInfo) time.Time {
+	if t, ok := accessTime(info); ok {
+		return t
+	}
+	return info.ModTime()
+}
+
+// ChangeTime returns when the metadata of the file described by info last
+// changed, or its modification time where that is not known.
+func ChangeTime(info os.FileInfo) time.Time {
+	if t, ok := changeTime(info); ok {
+		return t
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,271 @@
+package filter
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitIgnore tells which paths in a git work tree git ignores, following
+// the same files git does: the global excludes file, .git/info/exclude
+// and the .gitignore file of every directory. It reads them itself
+// instead of running git, and caches them, so asking about every entry of
+// a large tree stays cheap.
+type GitIgnore struct {
+	root    string
+	base    []gitRule            // global excludes and .git/info/exclude
+	rules   map[string][]gitRule // .gitignore rules by directory, relative to root
+	ignored map[string]bool      // directories already checked, relative to root
+}
+
+// gitRule is one line of an ignore file.
+type gitRule struct {
+	dir     string // directory of the ignore file, relative to the root
+	pattern Pattern
+	negate  bool
+}
+
+// NewGitIgnore returns the ignore rules for the work tree dir is in, or
+// nil if it is not in one.
+func NewGitIgnore(dir string) *GitIgnore {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	for {
+		if _, err := os.Lstat(filepath.Join(root, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return nil
+		}
+		root = parent
+	}
+
+	g := &GitIgnore{root: root, rules: map[string][]gitRule{}, ignored: map[string]bool{}}
+	gitDir := resolveGitDir(root)
+	if excludes := globalExcludesFile(gitDir); excludes != "" {
+		g.base = append(g.base, readIgnoreFile(excludes, "")...)
+	}
+	g.base = append(g.base, readIgnoreFile(filepath.Join(gitDir, "info", "exclude"), "")...)
+	return g
+}
+
+// Ignored reports whether git ignores the file or directory name.
+// Anything inside an ignored directory is ignored too, as is the .git
+// directory itself.
+func (g *GitIgnore) Ignored(name string, isDir bool) bool {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(g.root, abs)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if parent := path.Dir(rel); parent != "." && g.dirIgnored(parent) {
+		return true
+	}
+	return g.match(rel, isDir)
+}
+
+// dirIgnored reports whether the directory at rel, or one of its parents,
+// is ignored.
+func (g *GitIgnore) dirIgnored(rel string) bool {
+	if ignored, ok := g.ignored[rel]; ok {
+		return ignored
+	}
+	ignored := false
+	if parent := path.Dir(rel); parent != "." {
+		ignored = g.dirIgnored(parent)
+	}
+	ignored = ignored || g.match(rel, true)
+	g.ignored[rel] = ignored
+	return ignored
+}
+
+// match applies the rules for rel, whose parents are not ignored. The
+// last matching rule decides, and rules from deeper ignore files come
+// after those from higher up, so they take precedence.
+func (g *GitIgnore) match(rel string, isDir bool) bool {
+	if rel == ".git" || strings.HasSuffix(rel, "/.git") {
+		return true
+	}
+	rules := g.base
+	dir := ""
+	for _, component := range strings.Split(path.Dir(rel), "/") {
+		rules = append(rules[:len(rules):len(rules)], g.dirRules(dir)...)
+		if component != "." {
+			dir = path.Join(dir, component)
+		}
+	}
+	if dir != "" {
+		rules = append(rules, g.dirRules(dir)...)
+	}
+
+	ignored := false
+	for _, rule := range rules {
+		relToRule := rel
+		if rule.dir != "" {
+			relToRule = strings.TrimPrefix(rel, rule.dir+"/")
+		}
+		if rule.pattern.Match(relToRule, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// dirRules returns the rules of the .gitignore file in dir, relative to
+// the root, reading it the first time.
+func (g *GitIgnore) dirRules(dir string) []gitRule {
+	rules, ok := g.rules[dir]
+	if !ok {
+		rules = readIgnoreFile(filepath.Join(g.root, filepath.FromSlash(dir), ".gitignore"), dir)
+		g.rules[dir] = rules
+	}
+	return rules
+}
+
+// readIgnoreFile parses the ignore file at name, which applies to dir. A
+// missing or unreadable file has no rules.
+func readIgnoreFile(name, dir string) []gitRule {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var rules []gitRule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseGitRule(scanner.Text(), dir); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseGitRule parses a line of a gitignore file, as described in
+// gitignore(5).
+func parseGitRule(line, dir string) (gitRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are ignored unless escaped with a backslash.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return gitRule{}, false
+	}
+
+	rule := gitRule{dir: dir}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.pattern.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return gitRule{}, false
+	}
+	rule.pattern.segments = strings.Split(negateClasses(line), "/")
+	for _, segment := range rule.pattern.segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return gitRule{}, false
+		}
+	}
+	return rule, true
+}
+
+// resolveGitDir returns the git directory of the work tree at root, which
+// is .git itself unless .git is a file pointing elsewhere, as it is in
+// linked work trees and submodules.
+func resolveGitDir(root string) string {
+	gitDir := filepath.Join(root, ".git")
+	content, err := os.ReadFile(gitDir)
+	if err != nil {
+		return gitDir
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return gitDir
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	return target
+}
+
+// globalExcludesFile returns the path of the global excludes file: the
+// core.excludesFile setting of the repository or user configuration, or
+// git's default location.
+func globalExcludesFile(gitDir string) string {
+	home, _ := os.UserHomeDir()
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" && home != "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	excludes := ""
+	if configHome != "" {
+		excludes = filepath.Join(configHome, "git", "ignore")
+	}
+	configs := []string{filepath.Join(gitDir, "config")}
+	if home != "" {
+		configs = append(configs, filepath.Join(home, ".gitconfig"))
+	}
+	if configHome != "" {
+		configs = append(configs, filepath.Join(configHome, "git", "config"))
+	}
+	// The repository configuration overrides the user's.
+	for _, config := range configs {
+		if value, ok := readExcludesFile(config); ok {
+			excludes = value
+			break
+		}
+	}
+	if rest, ok := strings.CutPrefix(excludes, "~/"); ok && home != "" {
+		excludes = filepath.Join(home, rest)
+	}
+	return excludes
+}
+
+// readExcludesFile returns the value of core.excludesFile in the git
+// configuration file at name.
+func readExcludesFile(name string) (string, bool) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			section = strings.ToLower(strings.Trim(line, "[] \t"))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != "core" || !strings.EqualFold(strings.TrimSpace(key), "excludesfile") {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(value), `"`), true
+	}
+	return "", false
+}
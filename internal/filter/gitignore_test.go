@@ -0,0 +1,168 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGitRule(t *testing.T) {
+	tests := []struct {
+		line    string
+		ok      bool
+		negate  bool
+		dirOnly bool
+		relPath string
+		isDir   bool
+		match   bool
+	}{
+		{line: "", ok: false},
+		{line: "   ", ok: false},
+		{line: "# comment", ok: false},
+		{line: "/", ok: false},
+		{line: "*.o", ok: true, relPath: "src/main.o", match: true},
+		{line: "*.o\r", ok: true, relPath: "main.o", match: true},
+
+		// Trailing spaces are dropped unless escaped.
+		{line: "*.o   ", ok: true, relPath: "main.o", match: true},
+		{line: `name\ `, ok: true, relPath: "name ", match: true},
+		{line: `name\ `, ok: true, relPath: "name", match: false},
+		{line: `name\  `, ok: true, relPath: "name ", match: true},
+
+		// "!" negates, unless escaped, and so does "\#" keep a "#".
+		{line: "!keep.o", ok: true, negate: true, relPath: "keep.o", match: true},
+		{line: `\!important`, ok: true, relPath: "!important", match: true},
+		{line: `\#notes`, ok: true, relPath: "#notes", match: true},
+
+		// fnmatch negates classes with "!".
+		{line: "*.[!o]", ok: true, relPath: "main.c", match: true},
+		{line: "*.[!o]", ok: true, relPath: "main.o", match: false},
+		{line: "[!.]*", ok: true, relPath: ".hidden", match: false},
+
+		// A slash anywhere but at the end anchors the pattern to the
+		// directory of the ignore file.
+		{line: "/build", ok: true, relPath: "build", isDir: true, match: true},
+		{line: "/build", ok: true, relPath: "src/build", isDir: true, match: false},
+		{line: "doc/*.html", ok: true, relPath: "doc/index.html", match: true},
+		{line: "doc/*.html", ok: true, relPath: "src/doc/index.html", match: false},
+		{line: "**/logs", ok: true, relPath: "a/b/logs", isDir: true, match: true},
+		{line: "logs/**", ok: true, relPath: "logs/a/b", match: true},
+
+		// A trailing slash only matches directories.
+		{line: "out/", ok: true, dirOnly: true, relPath: "out", isDir: true, match: true},
+		{line: "out/", ok: true, dirOnly: true, relPath: "out", match: false},
+		{line: "out/", ok: true, dirOnly: true, relPath: "src/out", isDir: true, match: true},
+
+		{line: "[", ok: false},
+	}
+	for _, test := range tests {
+		rule, ok := parseGitRule(test.line, "")
+		if ok != test.ok {
+			t.Errorf("parseGitRule(%q) ok = %v, want %v", test.line, ok, test.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if rule.negate != test.negate || rule.pattern.dirOnly != test.dirOnly {
+			t.Errorf("parseGitRule(%q) negate, dirOnly = %v, %v, want %v, %v", test.line, rule.negate, rule.pattern.dirOnly, test.negate, test.dirOnly)
+		}
+		if got := rule.pattern.Match(test.relPath, test.isDir); got != test.match {
+			t.Errorf("parseGitRule(%q) matches %q (isDir=%v) = %v, want %v", test.line, test.relPath, test.isDir, got, test.match)
+		}
+	}
+}
+
+// newWorkTree creates a work tree holding files, keyed by slash-separated
+// path, with a home directory of its own so the user's git configuration
+// does not interfere.
+func newWorkTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestGitIgnore(t *testing.T) {
+	root := newWorkTree(t, map[string]string{
+		".gitignore":          "*.log\nbuild/\n!keep.log\n/top.txt\n",
+		".git/info/exclude":   "secret\n",
+		"src/.gitignore":      "!debug.log\ngen/\n*.tmp\n",
+		"src/deep/.gitignore": "!*.tmp\n",
+	})
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"keep.log", false, false}, // negated after the pattern
+		{"src/debug.log", false, false},
+		{"src/other.log", false, true},
+		{"src/deep/other.log", false, true},
+		{"build", true, true},
+		{"build", false, false},         // only directories
+		{"build/keep.log", false, true}, // nothing in an ignored directory comes back
+		{"src/gen/file.go", false, true},
+		{"gen", true, false}, // src/.gitignore does not apply above src
+		{"top.txt", false, true},
+		{"src/top.txt", false, false}, // anchored to the root
+		{"src/a.tmp", false, true},
+		{"src/deep/a.tmp", false, false}, // deeper files take precedence
+		{"secret", false, true},
+		{".git", true, true},
+		{"main.go", false, false},
+	}
+	g := NewGitIgnore(root)
+	if g == nil {
+		t.Fatal("NewGitIgnore returned nil inside a work tree")
+	}
+	for _, test := range tests {
+		if got := g.Ignored(filepath.Join(root, filepath.FromSlash(test.path)), test.isDir); got != test.ignored {
+			t.Errorf("Ignored(%q, isDir=%v) = %v, want %v", test.path, test.isDir, got, test.ignored)
+		}
+	}
+	if g.Ignored(root, true) {
+		t.Error("the root of the work tree is ignored")
+	}
+	if g.Ignored(filepath.Dir(root), true) {
+		t.Error("a path outside of the work tree is ignored")
+	}
+}
+
+func TestGitIgnoreExcludesFile(t *testing.T) {
+	root := newWorkTree(t, map[string]string{
+		".git/config": "[core]\n\texcludesFile = ~/ignores\n",
+	})
+	home := os.Getenv("HOME")
+	if err := os.WriteFile(filepath.Join(home, "ignores"), []byte("*.swp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("!keep.swp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGitIgnore(root)
+	if !g.Ignored(filepath.Join(root, "a.swp"), false) {
+		t.Error("the global excludes file is not applied")
+	}
+	if g.Ignored(filepath.Join(root, "keep.swp"), false) {
+		t.Error(".gitignore does not override the global excludes file")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package filter
+
+import "os"
+
+// owner reports false: files have no uid and gid on this platform.
+func owner(info os.FileInfo) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
+
+// inode reports false: files have no inode number on this platform.
+func inode(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
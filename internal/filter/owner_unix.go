@@ -0,0 +1,26 @@
+//go:build unix
+
+package filter
+
+import (
+	"os"
+	"syscall"
+)
+
+// owner returns the uid and gid of the file described by info.
+func owner(info os.FileInfo) (uid, gid uint32, ok bool) {
+	sys, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return sys.Uid, sys.Gid, true
+}
+
+// inode returns the inode number of the file described by info.
+func inode(info os.FileInfo) (uint64, bool) {
+	sys, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(sys.Ino), true
+}
@@ -0,0 +1,147 @@
+// Package filter decides which entries a listing shows.
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Pattern matches entries by name or by their path relative to the listed
+// directory.
+//
+// A glob pattern without a slash is matched against the name, so "*.pyc"
+// matches at any depth. A glob pattern with a slash is matched against the
+// whole relative path, where "**" stands for any number of directories, as
+// in "build/**/*.o" or "**/testdata". Character classes are negated with
+// "!" or "^". A trailing slash restricts a glob
+// pattern to directories. A pattern starting with "re:" is a regular
+// expression matched against the name.
+type Pattern struct {
+	segments []string // glob segments, nil for a regular expression
+	anchored bool     // match segments against the path instead of the name
+	dirOnly  bool
+	re       *regexp.Regexp
+}
+
+// Parse parses spec, a list of patterns separated by "|" like the argument
+// of tree -I. Empty patterns are skipped.
+func Parse(spec string) ([]Pattern, error) {
+	var patterns []Pattern
+	for _, text := range strings.Split(spec, "|") {
+		if text == "" {
+			continue
+		}
+		pattern, err := Compile(text)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// Compile parses a single pattern.
+func Compile(text string) (Pattern, error) {
+	if expr, ok := strings.CutPrefix(text, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return Pattern{}, fmt.Errorf("invalid pattern %q: %w", text, err)
+		}
+		return Pattern{re: re}, nil
+	}
+
+	var p Pattern
+	if strings.HasSuffix(text, "/") {
+		p.dirOnly = true
+		text = strings.TrimRight(text, "/")
+	}
+	if strings.Contains(text, "/") {
+		p.anchored = true
+		text = strings.TrimPrefix(text, "/")
+	}
+	p.segments = strings.Split(negateClasses(text), "/")
+	for _, segment := range p.segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return Pattern{}, fmt.Errorf("invalid pattern %q: %w", text, err)
+		}
+	}
+	return p, nil
+}
+
+// Match reports whether p matches the entry at relPath, a slash-separated
+// path relative to the listed directory.
+func (p Pattern) Match(relPath string, isDir bool) bool {
+	name := path.Base(relPath)
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if !p.anchored {
+		ok, _ := path.Match(p.segments[0], name)
+		return ok
+	}
+	return matchSegments(p.segments, strings.Split(relPath, "/"))
+}
+
+// negateClasses rewrites the character classes of glob that are negated
+// with "!", as in the shell and fnmatch, to the "^" path.Match expects.
+// Escaped brackets and brackets inside a class are left alone.
+func negateClasses(glob string) string {
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		b.WriteByte(c)
+		switch {
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteByte(glob[i])
+		case c == '[' && !inClass:
+			inClass = true
+			if i+1 < len(glob) && glob[i+1] == '!' {
+				b.WriteByte('^')
+				i++
+			}
+		case c == ']' && inClass:
+			inClass = false
+		}
+	}
+	return b.String()
+}
+
+// matchSegments matches glob segments against path segments, letting "**"
+// stand for any number of them.
+func matchSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for skip := 0; skip <= len(names); skip++ {
+				if matchSegments(patterns[1:], names[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(patterns[0], names[0]); !ok {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
+
+// MatchAny reports whether any of patterns matches the entry at relPath.
+func MatchAny(patterns []Pattern, relPath string, isDir bool) bool {
+	for _, p := range patterns {
+		if p.Match(relPath, isDir) {
+			return true
+		}
+	}
+	return false
+}
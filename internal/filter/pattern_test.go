@@ -0,0 +1,109 @@
+package filter
+
+import "testing"
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		relPath string
+		isDir   bool
+		want    bool
+	}{
+		// Without a slash, the name is matched at any depth.
+		{"*.pyc", "a.pyc", false, true},
+		{"*.pyc", "src/pkg/a.pyc", false, true},
+		{"*.pyc", "a.py", false, false},
+		{"vendor", "third_party/vendor", true, true},
+		{"[!a]*", "b.txt", false, true},
+		{"[!a]*", "a.txt", false, false},
+
+		// With a slash, the whole relative path is.
+		{"build/*.o", "build/main.o", false, true},
+		{"build/*.o", "src/build/main.o", false, false},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+
+		// "**" stands for any number of directories, including none.
+		{"build/**/*.o", "build/main.o", false, true},
+		{"build/**/*.o", "build/a/b/main.o", false, true},
+		{"build/**/*.o", "src/build/main.o", false, false},
+		{"**/testdata", "testdata", true, true},
+		{"**/testdata", "a/b/testdata", true, true},
+		{"**/testdata", "a/b/testdata/x", false, false},
+		{"src/**", "src/a/b", false, true},
+		{"src/**", "src", true, true},
+		{"a/**/b/**/c", "a/x/b/y/z/c", false, true},
+		{"a/**/b/**/c", "a/x/c", false, false},
+
+		// A trailing slash only matches directories.
+		{"node_modules/", "node_modules", true, true},
+		{"node_modules/", "node_modules", false, false},
+		{"src/gen/", "src/gen", true, true},
+		{"src/gen/", "src/gen", false, false},
+
+		// "re:" matches a regular expression against the name.
+		{`re:^\d+\.log$`, "logs/2024.log", false, true},
+		{`re:^\d+\.log$`, "2024.log.gz", false, false},
+		{`re:^src$`, "src", false, true},
+	}
+	for _, test := range tests {
+		p, err := Compile(test.pattern)
+		if err != nil {
+			t.Errorf("Compile(%q) failed: %v", test.pattern, err)
+			continue
+		}
+		if got := p.Match(test.relPath, test.isDir); got != test.want {
+			t.Errorf("%q.Match(%q, isDir=%v) = %v, want %v", test.pattern, test.relPath, test.isDir, got, test.want)
+		}
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	for _, text := range []string{"[", "src/[a-", "re:(", "re:a**"} {
+		if _, err := Compile(text); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	patterns, err := Parse("node_modules|vendor||*.pyc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 3 {
+		t.Fatalf("Parse returned %d patterns, want 3 with the empty one skipped", len(patterns))
+	}
+	for _, relPath := range []string{"node_modules", "a/vendor", "b/c.pyc"} {
+		if !MatchAny(patterns, relPath, true) {
+			t.Errorf("MatchAny(%q) = false, want true", relPath)
+		}
+	}
+	if MatchAny(patterns, "src/main.go", false) {
+		t.Error(`MatchAny("src/main.go") = true, want false`)
+	}
+
+	if _, err := Parse("ok|["); err == nil {
+		t.Error(`Parse("ok|[") succeeded, want an error`)
+	}
+}
+
+func TestNegateClasses(t *testing.T) {
+	tests := []struct {
+		glob, want string
+	}{
+		{"[!a]*", "[^a]*"},
+		{"[^a]*", "[^a]*"},
+		{"*.[!o]", "*.[^o]"},
+		{"[a!]", "[a!]"},
+		{`\[!a]`, `\[!a]`},
+		{"[[!]x", "[[!]x"},
+		{"[!a][!b]", "[^a][^b]"},
+		{"!a", "!a"},
+	}
+	for _, test := range tests {
+		if got := negateClasses(test.glob); got != test.want {
+			t.Errorf("negateClasses(%q) = %q, want %q", test.glob, got, test.want)
+		}
+	}
+}
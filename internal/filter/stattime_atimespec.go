@@ -0,0 +1,25 @@
+//go:build darwin || freebsd || netbsd
+
+package filter
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func accessTime(info os.FileInfo) (time.Time, bool) {
+	sys, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(sys.Atimespec.Unix()), true
+}
+
+func changeTime(info os.FileInfo) (time.Time, bool) {
+	sys, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(sys.Ctimespec.Unix()), true
+}
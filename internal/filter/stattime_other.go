@@ -0,0 +1,16 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+
+package filter
+
+import (
+	"os"
+	"time"
+)
+
+func accessTime(info os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func changeTime(info os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
@@ -0,0 +1,78 @@
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/osfiles"
+)
+
+// jsonRecord is the machine-readable form of a long listing entry.
+type jsonRecord struct {
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	Type        string    `json:"type"`
+	Mode        uint32    `json:"mode"`
+	Permissions string    `json:"permissions"`
+	UID         uint32    `json:"uid"`
+	GID         uint32    `json:"gid"`
+	User        string    `json:"user"`
+	Group       string    `json:"group"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mtime"`
+	Inode       uint64    `json:"inode"`
+	LinkTarget  string    `json:"link_target,omitempty"`
+	Kind        string    `json:"kind,omitempty"`
+}
+
+func newJSONRecord(details fileDetails) jsonRecord {
+	file := details.info
+	return jsonRecord{
+		Name:        file.Name(),
+		Path:        details.path,
+		Type:        osfiles.FileType(file.Mode()),
+		Mode:        details.mode,
+		Permissions: permissionString(file.Mode()),
+		UID:         details.uid,
+		GID:         details.gid,
+		User:        details.user,
+		Group:       details.group,
+		Size:        file.Size(),
+		ModTime:     file.ModTime(),
+		Inode:       details.inode,
+		LinkTarget:  details.linkTarget,
+		Kind:        details.kind,
+	}
+}
+
+// printJSON writes the entries of dir to stdout as a JSON array, one object
+// per entry, without any styling. The kind is only included with --kind.
+func printJSON(dir string, files []os.DirEntry, config config.Config) {
+	records := []jsonRecord{}
+	for _, file := range files {
+		fileInfo, err := file.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		details, err := getFileDetails(dir, fileInfo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		if config.ShowKind {
+			details.kind = kindOf(fileInfo, details.path)
+		}
+		records = append(records, newJSONRecord(details))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -13,17 +13,16 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/SiirRandall/lsd-go/internal/config"
 	"github.com/SiirRandall/lsd-go/internal/osfiles"
+	"github.com/SiirRandall/lsd-go/internal/sniff"
 	"github.com/SiirRandall/lsd-go/internal/style"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
 const (
-	linkcolor  = "#BE67F5"
-	executable = "#ff0303" //"#b00000"
-	whiteColor = "#FFFFFF"
-	center     = lipgloss.Center
+	center = lipgloss.Center
 )
 
 type maxLen struct {
@@ -31,13 +30,48 @@ type maxLen struct {
 	groupLen   int
 	sizeNumLen int
 	inodeLen   int
+	kindLen    int
 }
 
-var noColor *bool
+// fileDetails holds the raw, unstyled data shown for a single entry in the
+// long listing.
+type fileDetails struct {
+	info       os.FileInfo
+	path       string
+	uid        uint32
+	gid        uint32
+	user       string
+	group      string
+	inode      uint64
+	mode       uint32
+	linkTarget string
+	kind       string // only filled in when the Kind column is shown
+}
 
-func ListFiles(args []string, showDotFiles bool, showInodes bool, headers bool, noColor bool) {
-	files, dir := osfiles.GetFiles(args, showDotFiles)
+func ListFiles(config config.Config) {
+	files, dir := osfiles.GetFiles(config)
+	showInodes, headers := config.ShowInodes, config.Headers
+	if config.NullTerminate {
+		osfiles.PrintNullTerminated(osfiles.Names(files))
+		return
+	}
+	if config.Template != "" {
+		printTemplate(dir, files, config)
+		return
+	}
+	switch config.Format {
+	case "json":
+		printJSON(dir, files, config)
+		return
+	case "csv", "tsv":
+		printDelimited(dir, files, config)
+		return
+	case "markdown":
+		printMarkdown(dir, files, config)
+		return
+	}
 	max := maxLen{}
+	kinds := map[string]string{}
 	for _, file := range files {
 		fileInfo, err := file.Info()
 		if err != nil {
@@ -67,19 +101,32 @@ func ListFiles(args []string, showDotFiles bool, showInodes bool, headers bool,
 		if inodeLen > max.inodeLen {
 			max.inodeLen = inodeLen
 		}
+		if config.ShowKind {
+			kind := kindOf(fileInfo, filepath.Join(dir, fileInfo.Name()))
+			kinds[fileInfo.Name()] = kind
+			if len(kind) > max.kindLen {
+				max.kindLen = len(kind)
+			}
+		}
 	}
 	if headers {
 		if max.inodeLen < 6 {
 			max.inodeLen = 6
 		}
 
-		inodeHeaderStyle := createHeaderStyle(whiteColor, max.inodeLen, center, "Inodes")
-		permHeaderStyle := createHeaderStyle(whiteColor, 11, center, "Permissions")
-		userHeaderStyle := createHeaderStyle(whiteColor, max.userLen, center, "User")
-		groupHeaderStyle := createHeaderStyle(whiteColor, max.groupLen, center, "Group")
-		sizeHeaderStyle := createHeaderStyle(whiteColor, max.sizeNumLen+2, center, "Size")
-		timeHeaderStyle := createHeaderStyle(whiteColor, 24, center, "Last Modified")
-		nameStyle := createHeaderStyle(whiteColor, 0, center, "Name") // Adjust width as necessary
+		inodeHeaderStyle := createHeaderStyle(style.Current.Header, max.inodeLen, center, "Inodes")
+		permHeaderStyle := createHeaderStyle(style.Current.Header, 11, center, "Permissions")
+		userHeaderStyle := createHeaderStyle(style.Current.Header, max.userLen, center, "User")
+		groupHeaderStyle := createHeaderStyle(style.Current.Header, max.groupLen, center, "Group")
+		sizeHeaderStyle := createHeaderStyle(style.Current.Header, max.sizeNumLen+2, center, "Size")
+		timeHeaderStyle := createHeaderStyle(style.Current.Header, 24, center, "Last Modified")
+		if config.ShowKind {
+			if max.kindLen < 4 {
+				max.kindLen = 4
+			}
+			timeHeaderStyle += " " + createHeaderStyle(style.Current.Header, max.kindLen, center, "Kind")
+		}
+		nameStyle := createHeaderStyle(style.Current.Header, 0, center, "Name") // Adjust width as necessary
 
 		if headers && showInodes {
 			fmt.Printf("%-s  %-s %-s %-s %s %-s %s %s\n",
@@ -98,45 +145,111 @@ func ListFiles(args []string, showDotFiles bool, showInodes bool, headers bool,
 			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
 			continue // skip to the next iteration
 		}
-		printFileDetails(dir, fileInfo, max, showInodes, headers, noColor)
+		details, err := getFileDetails(dir, fileInfo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		details.kind = kinds[fileInfo.Name()]
+		printFileDetails(details, max, config)
+	}
+}
+
+func printTemplate(dir string, files []os.DirEntry, config config.Config) {
+	tmpl, err := ParseTemplate(config.Template, config.NoColor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+	for _, file := range files {
+		fileInfo, err := file.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		out, err := tmpl.Render(dir, fileInfo, style.EntryIcon(filepath.Join(dir, fileInfo.Name()), fileInfo))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	}
+}
+
+// kindOf describes file, which lives at path: its type if it is not a
+// regular file, and what its content looks like otherwise.
+func kindOf(file os.FileInfo, path string) string {
+	if !file.Mode().IsRegular() {
+		return osfiles.FileType(file.Mode())
+	}
+	return sniff.File(path, file).Description
+}
+
+// getFileDetails gathers everything the long listing needs to know about
+// file, independent of how it is going to be rendered.
+func getFileDetails(dir string, file os.FileInfo) (fileDetails, error) {
+	details := fileDetails{info: file, path: filepath.Join(dir, file.Name())}
+	details.user, details.group = getUserAndGroup(file, dir)
+
+	if sys, ok := file.Sys().(*syscall.Stat_t); ok {
+		details.uid = sys.Uid
+		details.gid = sys.Gid
+		details.inode = sys.Ino
+		details.mode = uint32(sys.Mode)
+	}
+
+	if file.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(details.path)
+		if err != nil {
+			return details, fmt.Errorf("reading symlink target: %w", err)
+		}
+		details.linkTarget = target
 	}
+	return details, nil
 }
 
-func printFileDetails(dir string, file os.FileInfo, max maxLen, showInodes bool, headers bool, noColor bool) {
-	permStyledString := getPermissionStyle(file, noColor)
-	user, group := getUserAndGroup(file, dir)
+// printFileDetails prints the long listing line for details, with columns
+// as wide as max and the ones config asks for.
+func printFileDetails(details fileDetails, max maxLen, config config.Config) {
+	file := details.info
+	permStyledString := getPermissionStyle(file, config.NoColor)
+	user, group := details.user, details.group
 	sizeNum, sizeUnit := formatSize(file.Size())
 	sizeStyle, color := getSizeStyleAndColor(file.Size())
 
-	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#fcfbd2")).Width(max.userLen)
-	groupStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#d1d0ab")).Width(max.groupLen)
-	fileNameStyle, nerdFontSymbol := getFileNameStyle(file)
-	styledFileName := fileNameStyle.Render(nerdFontSymbol + file.Name())
+	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(style.Current.User)).Width(max.userLen)
+	groupStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(style.Current.Group)).Width(max.groupLen)
+	fileNameStyle, nerdFontSymbol := getFileNameStyle(file, details.path)
+	// Like ls -lF, a symlink is not marked when its target is shown; the
+	// target is marked instead.
+	indicator := ""
+	if file.Mode()&os.ModeSymlink == 0 {
+		indicator = osfiles.Indicator(file.Mode(), config.IndicatorStyle)
+	}
+	styledFileName := fileNameStyle.Render(nerdFontSymbol + file.Name() + indicator)
+	if config.Hyperlinks {
+		// The name is the last, unpadded column, so the invisible escape
+		// sequence cannot throw off the alignment of the other columns.
+		styledFileName = style.Hyperlink(styledFileName, details.path)
+	}
 
 	if file.Mode()&os.ModeSymlink != 0 {
-		targetPath, err := os.Readlink(filepath.Join(dir, file.Name()))
-		if err != nil {
-			fmt.Println("Error reading symlink target:", err)
-			return
+		target := details.linkTarget
+		if targetInfo, err := os.Stat(details.path); err == nil {
+			target += osfiles.Indicator(targetInfo.Mode(), config.IndicatorStyle)
 		}
-		styledFileName += " ⇒ " + lipgloss.NewStyle().Foreground(lipgloss.Color(linkcolor)).Render(targetPath)
+		styledFileName += " ⇒ " + getLinkTargetStyle(details).Render(target)
 	}
 
 	var inodeStyle lipgloss.Style
 	var styledString string
-	if showInodes {
-		sys, ok := file.Sys().(*syscall.Stat_t)
-		if !ok {
-			fmt.Fprintf(os.Stderr, "error retrieving file info: \n")
-			return
-		}
-		inode := sys.Ino
-		color = "#FFFFFF"
+	if config.ShowInodes {
+		color = style.Current.Inode
 		inodeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Width(max.inodeLen)
-		styledString = inodeStyle.Render(strconv.Itoa(int(inode)))
+		styledString = inodeStyle.Render(strconv.FormatUint(details.inode, 10))
 		// lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Width(max.inodeLen).Render(strconv.Itoa(int(inode)))
 	}
-	if headers {
+	if config.Headers {
 		permStyledString += " "
 	}
 
@@ -147,11 +260,15 @@ func printFileDetails(dir string, file os.FileInfo, max maxLen, showInodes bool,
 		sizeStyle.Align(lipgloss.Right).Width(max.sizeNumLen).Render(sizeNum),
 		lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Width(2).Render(sizeUnit),
 		getTimeStyle(file.ModTime()).Width(24).Render(file.ModTime().Format("Mon Jan 02 15:04:05 2006")),
-		styledFileName,
 	}
+	if config.ShowKind {
+		kindStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(style.Current.File)).Width(max.kindLen)
+		format = append(format, kindStyle.Render(details.kind))
+	}
+	format = append(format, styledFileName)
 
-	if showInodes {
-		if headers {
+	if config.ShowInodes {
+		if config.Headers {
 			format = append([]interface{}{styledString, ""}, format...)
 		} else {
 			format = append([]interface{}{styledString}, format...)
@@ -233,14 +350,32 @@ func formatSize(size int64) (string, string) {
 	}
 }
 
+// HumanSize formats size the way the long listing does, e.g. "4.0 KB".
+func HumanSize(size int64) string {
+	num, unit := formatSize(size)
+	return num + " " + unit
+}
+
+// permissionString returns the unstyled permission string for mode,
+// e.g. "drwxr-xr-x".
+func permissionString(mode os.FileMode) string {
+	typeChar := "-"
+	if mode&os.ModeSymlink != 0 {
+		typeChar = "l"
+	} else if mode.IsDir() {
+		typeChar = "d"
+	}
+	return typeChar + formatPermissions(mode)
+}
+
 func getPermissionStyle(fileInfo os.FileInfo, noColor bool) string {
 	perm := fileInfo.Mode()
 	var b strings.Builder
 
 	if fileInfo.Mode()&os.ModeSymlink != 0 {
-		b.WriteString(colorize("l", linkcolor, noColor)) // Cyan for symlink
+		b.WriteString(colorize("l", style.Current.Symlink, noColor))
 	} else if fileInfo.IsDir() {
-		b.WriteString(colorize("d", "#00FFFF", noColor)) // Cyan for directory
+		b.WriteString(colorize("d", style.Current.Directory, noColor))
 	} else {
 		b.WriteString("-")
 	}
@@ -249,15 +384,15 @@ func getPermissionStyle(fileInfo os.FileInfo, noColor bool) string {
 	for _, c := range permStr {
 		switch c {
 		case 'r':
-			b.WriteString(colorize(string(c), "#00FF00", noColor)) // Green
+			b.WriteString(colorize(string(c), style.Current.Read, noColor))
 		case 'w':
-			b.WriteString(colorize(string(c), "#FFA500", noColor)) // Orange
+			b.WriteString(colorize(string(c), style.Current.Write, noColor))
 		case 'x':
-			b.WriteString(colorize(string(c), "#FF0000", noColor)) // Red
+			b.WriteString(colorize(string(c), style.Current.Execute, noColor))
 		case 's':
-			b.WriteString(colorize(string(c), "#FFD700", noColor)) // Gold
+			b.WriteString(colorize(string(c), style.Current.SetID, noColor))
 		case 't':
-			b.WriteString(colorize(string(c), "#FFC0CB", noColor)) // Pink
+			b.WriteString(colorize(string(c), style.Current.Sticky, noColor))
 		case '-':
 			b.WriteString(colorize(string(c), "", noColor)) // No color
 		}
@@ -276,19 +411,19 @@ func colorize(input string, color string, noColor bool) string {
 
 func getSizeStyleAndColor(size int64) (lipgloss.Style, string) {
 	if size < 1024*1024 { // KB
-		color := "#FFFFFF" // replace with the exact white you desire
+		color := style.Current.SizeSmall
 		return lipgloss.NewStyle().Foreground(lipgloss.Color(color)), color
 	} else { // MB
-		color := "#FFA500" // replace with the exact orange you desire
+		color := style.Current.SizeLarge
 		return lipgloss.NewStyle().Foreground(lipgloss.Color(color)), color
 	}
 }
 
 func getTimeStyle(t time.Time) lipgloss.Style {
 	if t.Day() == time.Now().Day() {
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("#02e05f")) // replace with the exact bright green you desire
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(style.Current.DateToday))
 	}
-	return lipgloss.NewStyle().Foreground(lipgloss.Color("#02bd91")) // replace with the exact dark green you desire
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(style.Current.DateOlder))
 }
 
 func formatPermissions(perm os.FileMode) string {
@@ -325,35 +460,38 @@ func ifThenElse(condition bool, a, b rune) rune {
 	return b
 }
 
-func getFileNameStyle(file os.FileInfo) (lipgloss.Style, string) {
-	name := file.Name()
-	var iconAndColor style.FileTypeIcon
-	var ok bool
-
-	if file.Mode()&os.ModeSymlink != 0 {
-		iconAndColor = style.FileTypeIcon{Icon: " ", Color: linkcolor}
-	} else if file.IsDir() {
-		iconAndColor, ok = style.FileTypeIconMap[name]
-		if !ok {
-			iconAndColor = style.FileTypeIcon{Icon: "\uf115 ", Color: "#00FFFF"}
-		}
-	} else if isBinary(file) {
-		iconAndColor = style.FileTypeIcon{Icon: "\uf489 ", Color: executable} // Green for binary files with bash icon
-	} else {
-		ext := strings.ToLower(filepath.Ext(name))
-		iconAndColor, ok = style.ExtToFileTypeIconMap[ext]
-		if !ok {
-			iconAndColor = style.FileTypeIcon{Icon: "\uf15b ", Color: "#FFFFFF"}
+func getFileNameStyle(file os.FileInfo, path string) (lipgloss.Style, string) {
+	iconAndColor := style.EntryIcon(path, file)
+	if style.NameColors != nil {
+		if nameStyle, ok := style.NameColors.Style(path, file); ok {
+			return style.DimIgnored(nameStyle, path, file.IsDir()), iconAndColor.Icon
 		}
 	}
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(iconAndColor.Color))
 
-	style := lipgloss.NewStyle().Foreground(lipgloss.Color(iconAndColor.Color))
-
-	return style, iconAndColor.Icon
+	return style.DimIgnored(nameStyle, path, file.IsDir()), iconAndColor.Icon
 }
 
-func isBinary(file os.FileInfo) bool {
-	return file.Mode().Perm()&0111 != 0 // checks if any of the executable bits are set
+// getLinkTargetStyle returns the style for the target of the symlink
+// described by details.
+func getLinkTargetStyle(details fileDetails) lipgloss.Style {
+	if style.NameColors != nil {
+		target := details.linkTarget
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(details.path), target)
+		}
+		var targetStyle lipgloss.Style
+		var ok bool
+		if info, err := os.Lstat(target); err == nil {
+			targetStyle, ok = style.NameColors.Style(target, info)
+		} else {
+			targetStyle, ok = style.NameColors.MissingStyle()
+		}
+		if ok {
+			return targetStyle
+		}
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(style.Current.Symlink))
 }
 
 func createHeaderStyle(color string, width int, align lipgloss.Position, text string) string {
@@ -0,0 +1,105 @@
+package list
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/style"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"~", `\~`,
+	"#", `\#`,
+	"&", `\&`,
+	"\r", "&#13;",
+	"\n", "&#10;",
+)
+
+// MarkdownEscape escapes s so it is shown literally in GitHub-flavored
+// Markdown, both in running text and inside table cells.
+func MarkdownEscape(s string) string {
+	s = markdownEscaper.Replace(s)
+
+	// A leading "-", "+" or "1." would otherwise start a nested list.
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return `\` + s
+	}
+	digits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+	if digits > 0 && digits < len(s) && (s[digits] == '.' || s[digits] == ')') {
+		return s[:digits] + `\` + s[digits:]
+	}
+	return s
+}
+
+// printMarkdown writes the long listing as a GitHub-flavored Markdown table.
+func printMarkdown(dir string, files []os.DirEntry, config config.Config) {
+	var b strings.Builder
+
+	var header, align []string
+	if config.ShowInodes {
+		header = append(header, "Inodes")
+		align = append(align, "---:")
+	}
+	header = append(header, "Permissions", "User", "Group", "Size", "Last Modified")
+	align = append(align, "---", "---", "---", "---:", "---")
+	if config.ShowKind {
+		header = append(header, "Kind")
+		align = append(align, "---")
+	}
+	header = append(header, "Name")
+	align = append(align, "---")
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString("| " + strings.Join(align, " | ") + " |\n")
+
+	for _, file := range files {
+		fileInfo, err := file.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		details, err := getFileDetails(dir, fileInfo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+
+		name := MarkdownEscape(fileInfo.Name())
+		if config.MarkdownIcons {
+			name = style.EntryIcon(details.path, fileInfo).Icon + name
+		}
+		if details.linkTarget != "" {
+			name += " ⇒ " + MarkdownEscape(details.linkTarget)
+		}
+
+		var row []string
+		if config.ShowInodes {
+			row = append(row, strconv.FormatUint(details.inode, 10))
+		}
+		row = append(row,
+			"`"+permissionString(fileInfo.Mode())+"`",
+			MarkdownEscape(details.user),
+			MarkdownEscape(details.group),
+			HumanSize(fileInfo.Size()),
+			fileInfo.ModTime().Format("Mon Jan 02 15:04:05 2006"),
+		)
+		if config.ShowKind {
+			row = append(row, MarkdownEscape(kindOf(fileInfo, details.path)))
+		}
+		row = append(row, name)
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+
+	fmt.Print(b.String())
+}
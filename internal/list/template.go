@@ -0,0 +1,86 @@
+package list
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/SiirRandall/lsd-go/internal/style"
+)
+
+// TemplateEntry is the data a --template is executed with, once per entry.
+type TemplateEntry struct {
+	Name       string      // base name of the entry
+	Path       string      // directory argument joined with Name
+	Size       int64       // size in bytes
+	Mode       os.FileMode // type and permission bits
+	ModTime    time.Time   // last modification time
+	User       string      // owner name, or uid if it cannot be resolved
+	Group      string      // group name, or gid if it cannot be resolved
+	Inode      uint64      // inode number
+	LinkTarget string      // symlink target, empty for anything but symlinks
+	Icon       string      // icon the current view would show
+	Color      string      // hex color the current view would use for the name
+}
+
+// Template renders entries through a user supplied text/template.
+type Template struct {
+	tmpl *template.Template
+}
+
+// ParseTemplate parses text as an entry template. Besides the text/template
+// builtins it provides:
+//
+//	size     human readable size as in the long listing, e.g. "4.0 KB"
+//	perms    ls style permission string for a Mode, e.g. "drwxr-xr-x"
+//	date     time formatted as in the long listing
+//	color    color text with a hex color, unless colors are disabled
+func ParseTemplate(text string, noColor bool) (*Template, error) {
+	funcs := template.FuncMap{
+		"size":  HumanSize,
+		"perms": permissionString,
+		"date": func(t time.Time) string {
+			return t.Format("Mon Jan 02 15:04:05 2006")
+		},
+		"color": func(color string, text string) string {
+			return colorize(text, color, noColor)
+		},
+	}
+	tmpl, err := template.New("entry").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Render executes the template for file, which lives in dir. icon is what
+// the calling view would display for the entry. A single trailing newline
+// is dropped so that templates read from files behave like inline ones.
+func (t *Template) Render(dir string, file os.FileInfo, icon style.FileTypeIcon) (string, error) {
+	details, err := getFileDetails(dir, file)
+	if err != nil {
+		return "", err
+	}
+
+	entry := TemplateEntry{
+		Name:       file.Name(),
+		Path:       details.path,
+		Size:       file.Size(),
+		Mode:       file.Mode(),
+		ModTime:    file.ModTime(),
+		User:       details.user,
+		Group:      details.group,
+		Inode:      details.inode,
+		LinkTarget: details.linkTarget,
+		Icon:       icon.Icon,
+		Color:      icon.Color,
+	}
+
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, entry); err != nil {
+		return "", fmt.Errorf("error executing template: %w", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
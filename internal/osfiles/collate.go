@@ -0,0 +1,67 @@
+package osfiles
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// compareNames orders names for the name sort key. SetCollation replaces
+// it; the default is Unicode collation without locale tailoring.
+var compareNames = newCollation(language.Und)
+
+// SetCollation chooses how names are ordered: "bytes" compares them byte
+// by byte, "unicode" by the Unicode Collation Algorithm, and "locale" by
+// the same algorithm tailored to the language in LC_ALL, LC_COLLATE or
+// LANG. A C or POSIX locale, or none at all, leaves it untailored.
+func SetCollation(mode string) error {
+	switch mode {
+	case "bytes":
+		compareNames = strings.Compare
+	case "unicode":
+		compareNames = newCollation(language.Und)
+	case "locale":
+		compareNames = newCollation(collationLocale())
+	default:
+		return fmt.Errorf("expected bytes, unicode or locale")
+	}
+	return nil
+}
+
+func newCollation(tag language.Tag) func(a, b string) int {
+	collator := collate.New(tag)
+	return func(a, b string) int {
+		if c := collator.CompareString(a, b); c != 0 {
+			return c
+		}
+		// Names that only differ in ignorable characters still need a
+		// fixed order.
+		return strings.Compare(a, b)
+	}
+}
+
+// collationLocale returns the language that names are collated for, as
+// POSIX determines it from the environment.
+func collationLocale() language.Tag {
+	locale := ""
+	for _, name := range []string{"LC_ALL", "LC_COLLATE", "LANG"} {
+		if locale = os.Getenv(name); locale != "" {
+			break
+		}
+	}
+	// "de_DE.UTF-8@euro" names the language "de-DE".
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return language.Und
+	}
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil {
+		return language.Und
+	}
+	return tag
+}
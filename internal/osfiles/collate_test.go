@@ -0,0 +1,79 @@
+package osfiles
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+// withCollation switches to the collation mode for the rest of the test.
+func withCollation(t *testing.T, mode string) {
+	t.Helper()
+	saved := compareNames
+	t.Cleanup(func() { compareNames = saved })
+	if err := SetCollation(mode); err != nil {
+		t.Fatalf("SetCollation(%q) failed: %v", mode, err)
+	}
+}
+
+func TestSetCollation(t *testing.T) {
+	tests := []struct {
+		mode   string
+		locale string // LC_ALL, for the locale mode
+		a, b   string
+		want   int
+	}{
+		{mode: "bytes", a: "zebra", b: "Éclair", want: -1}, // 'z' is 0x7a, 'É' starts with 0xc3
+		{mode: "unicode", a: "Éclair", b: "zebra", want: -1},
+		{mode: "bytes", a: "Zebra", b: "apple", want: -1}, // upper case first
+		{mode: "unicode", a: "apple", b: "Zebra", want: -1},
+		{mode: "unicode", a: "file", b: "File", want: -1}, // lower case first when otherwise equal
+		{mode: "unicode", a: "a", b: "a", want: 0},
+		// Names that collate as equal, here because of a soft hyphen, are
+		// still told apart.
+		{mode: "unicode", a: "a\u00ad", b: "a", want: 1},
+		// Swedish sorts ö after z, while the root collation puts it with o.
+		{mode: "unicode", a: "öl", b: "zebra", want: -1},
+		{mode: "locale", locale: "sv_SE.UTF-8", a: "zebra", b: "öl", want: -1},
+		{mode: "locale", locale: "C", a: "öl", b: "zebra", want: -1},
+		{mode: "locale", locale: "POSIX", a: "Éclair", b: "zebra", want: -1},
+	}
+	for _, test := range tests {
+		t.Setenv("LC_ALL", test.locale)
+		withCollation(t, test.mode)
+		if got := sign(compareNames(test.a, test.b)); got != test.want {
+			t.Errorf("%s collation (LC_ALL=%q): compare(%q, %q) = %d, want %d", test.mode, test.locale, test.a, test.b, got, test.want)
+		}
+		if got := sign(compareNames(test.b, test.a)); got != -test.want {
+			t.Errorf("%s collation (LC_ALL=%q): compare(%q, %q) = %d, want %d", test.mode, test.locale, test.b, test.a, got, -test.want)
+		}
+	}
+
+	if err := SetCollation("ascii"); err == nil {
+		t.Error(`SetCollation("ascii") succeeded, want an error`)
+	}
+}
+
+func TestCollationLocale(t *testing.T) {
+	tests := []struct {
+		lcAll, lcCollate, lang string
+		want                   language.Tag
+	}{
+		{"", "", "", language.Und},
+		{"", "", "sv_SE.UTF-8", language.MustParse("sv-SE")},
+		{"", "de_DE.UTF-8@euro", "sv_SE.UTF-8", language.MustParse("de-DE")},
+		{"fr_FR", "de_DE", "sv_SE", language.MustParse("fr-FR")},
+		{"C", "de_DE", "", language.Und},
+		{"POSIX.UTF-8", "", "", language.Und},
+		{"C.UTF-8", "", "", language.Und},
+		{"not a locale", "", "", language.Und},
+	}
+	for _, test := range tests {
+		t.Setenv("LC_ALL", test.lcAll)
+		t.Setenv("LC_COLLATE", test.lcCollate)
+		t.Setenv("LANG", test.lang)
+		if got := collationLocale(); got != test.want {
+			t.Errorf("collationLocale() with LC_ALL=%q LC_COLLATE=%q LANG=%q = %v, want %v", test.lcAll, test.lcCollate, test.lang, got, test.want)
+		}
+	}
+}
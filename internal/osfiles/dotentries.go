@@ -0,0 +1,40 @@
+package osfiles
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// dotEntry is the . or .. entry of a directory, which os.ReadDir leaves
+// out.
+type dotEntry struct {
+	info dotInfo
+}
+
+func (d dotEntry) Name() string               { return d.info.name }
+func (d dotEntry) IsDir() bool                { return true }
+func (d dotEntry) Type() os.FileMode          { return os.ModeDir }
+func (d dotEntry) Info() (os.FileInfo, error) { return d.info, nil }
+
+// dotInfo describes the directory a dot entry refers to, under the name
+// of the entry.
+type dotInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (d dotInfo) Name() string { return d.name }
+
+// dotEntries returns the . and .. entries of dir, leaving out any that
+// cannot be read.
+func dotEntries(dir string) []os.DirEntry {
+	var entries []os.DirEntry
+	for _, name := range []string{".", ".."} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, dotEntry{dotInfo{info, name}})
+	}
+	return entries
+}
@@ -0,0 +1,53 @@
+package osfiles
+
+import "os"
+
+// FileType returns a short, stable name for the type encoded in mode.
+func FileType(mode os.FileMode) string {
+	switch {
+	case mode&os.ModeSymlink != 0:
+		return "symlink"
+	case mode.IsDir():
+		return "directory"
+	case mode&os.ModeNamedPipe != 0:
+		return "pipe"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	case mode&os.ModeCharDevice != 0:
+		return "char_device"
+	case mode&os.ModeDevice != 0:
+		return "block_device"
+	case mode&os.ModeIrregular != 0:
+		return "irregular"
+	default:
+		return "file"
+	}
+}
+
+// Indicator returns the character ls appends to a name of the given mode
+// for the given --indicator-style: "slash" only marks directories,
+// "file-type" also marks symlinks, pipes and sockets, and "classify"
+// additionally marks executables.
+func Indicator(mode os.FileMode, style string) string {
+	if style == "" || style == "none" {
+		return ""
+	}
+	if mode.IsDir() {
+		return "/"
+	}
+	if style == "slash" {
+		return ""
+	}
+
+	switch {
+	case mode&os.ModeSymlink != 0:
+		return "@"
+	case mode&os.ModeNamedPipe != 0:
+		return "|"
+	case mode&os.ModeSocket != 0:
+		return "="
+	case style == "classify" && mode.IsRegular() && mode.Perm()&0111 != 0:
+		return "*"
+	}
+	return ""
+}
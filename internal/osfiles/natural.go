@@ -0,0 +1,92 @@
+package osfiles
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CompareNatural orders a and b the way people read them: runs of digits
+// compare by their numeric value, so "file2" sorts before "file10" and
+// "v1.9.0" before "v1.10.0", and letters compare without regard to case.
+// Digits from any script count, so "file٢" sorts like "file2".
+//
+// Names that are equal under these rules are told apart by the first
+// difference in leading zeros (fewer first, so "1" before "01"), then by
+// the first difference in case (upper case first), then byte by byte, so
+// the order is total.
+func CompareNatural(a, b string) int {
+	originalA, originalB := a, b
+	zeros, folded := 0, 0
+	for a != "" && b != "" {
+		ra, sizeA := utf8.DecodeRuneInString(a)
+		rb, sizeB := utf8.DecodeRuneInString(b)
+
+		if unicode.IsDigit(ra) && unicode.IsDigit(rb) {
+			numA, restA := digitRun(a)
+			numB, restB := digitRun(b)
+			valueA, valueB := strings.TrimLeft(numA, "0"), strings.TrimLeft(numB, "0")
+			if c := compareInts(int64(len(valueA)), int64(len(valueB))); c != 0 {
+				return c
+			}
+			if c := strings.Compare(valueA, valueB); c != 0 {
+				return c
+			}
+			if zeros == 0 {
+				zeros = compareInts(int64(len(numA)), int64(len(numB)))
+			}
+			a, b = restA, restB
+			continue
+		}
+
+		if ra != rb {
+			la, lb := unicode.ToLower(ra), unicode.ToLower(rb)
+			if la != lb {
+				return compareInts(int64(la), int64(lb))
+			}
+			if folded == 0 {
+				folded = compareInts(int64(ra), int64(rb))
+			}
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+	if c := compareInts(int64(len(a)), int64(len(b))); c != 0 {
+		return c
+	}
+	if zeros != 0 {
+		return zeros
+	}
+	if folded != 0 {
+		return folded
+	}
+	return strings.Compare(originalA, originalB)
+}
+
+// digitRun splits the run of digits at the start of s off the rest, with
+// the digits translated to ASCII.
+func digitRun(s string) (digits, rest string) {
+	var b strings.Builder
+	for s != "" {
+		r, size := utf8.DecodeRuneInString(s)
+		if !unicode.IsDigit(r) {
+			break
+		}
+		b.WriteByte('0' + byte(digitValue(r)))
+		s = s[size:]
+	}
+	return b.String(), s
+}
+
+// digitValue returns the value of the decimal digit r. Unicode encodes the
+// digits of each script as a contiguous run starting at zero, and a few
+// such runs sit back to back, hence the modulo.
+func digitValue(r rune) int {
+	if '0' <= r && r <= '9' {
+		return int(r - '0')
+	}
+	zero := r
+	for unicode.IsDigit(zero - 1) {
+		zero--
+	}
+	return int(r-zero) % 10
+}
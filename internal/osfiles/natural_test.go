@@ -0,0 +1,83 @@
+package osfiles
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCompareNatural(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"file2", "file10", -1},
+		{"file10", "file2", 1},
+		{"v1.9.0", "v1.10.0", -1},
+		{"a", "a", 0},
+		{"", "a", -1},
+		{"file", "file1", -1},
+		{"File", "file", -1}, // upper case first when otherwise equal
+		{"File2", "file10", -1},
+		{"1", "01", -1}, // fewer leading zeros first
+		{"01", "1", 1},
+		{"a01b", "a1c", -1}, // a later difference beats leading zeros
+		{"x007", "x7", 1},
+		{"99999999999999999999", "100000000000000000000", -1}, // beyond int64
+		{"file٢", "file10", -1},                               // Arabic-Indic two
+		{"file٢", "file2", 1},                                 // equal by value, told apart by bytes
+		{"file２", "file٢", 1},                                 // fullwidth two
+		{"a-1", "a_1", -1},
+	}
+	for _, test := range tests {
+		if got := sign(CompareNatural(test.a, test.b)); got != test.want {
+			t.Errorf("CompareNatural(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := sign(CompareNatural(test.b, test.a)); got != -test.want {
+			t.Errorf("CompareNatural(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestCompareNaturalSortsVersions(t *testing.T) {
+	names := []string{"v1.10", "v1.2", "V1.2", "v1.02", "v1.1", "v10", "v2"}
+	sort.Slice(names, func(i, j int) bool { return CompareNatural(names[i], names[j]) < 0 })
+	want := []string{"v1.1", "V1.2", "v1.2", "v1.02", "v1.10", "v2", "v10"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("sorted = %q, want %q", names, want)
+		}
+	}
+}
+
+func TestDigitValue(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want int
+	}{
+		{'0', 0},
+		{'7', 7},
+		{'٣', 3}, // Arabic-Indic
+		{'۹', 9}, // Extended Arabic-Indic
+		{'३', 3}, // Devanagari
+		{'８', 8}, // fullwidth
+		{'𝟎', 0}, // mathematical bold, where several runs of ten sit back to back
+		{'𝟗', 9},
+		{'𝟘', 0}, // mathematical double-struck, the second run
+		{'𝟡', 9},
+	}
+	for _, test := range tests {
+		if got := digitValue(test.r); got != test.want {
+			t.Errorf("digitValue(%q) = %d, want %d", test.r, got, test.want)
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -3,35 +3,136 @@ package osfiles
 import (
 	"fmt"
 	"os"
-	"sort"
+	"path"
+	"path/filepath"
 	"strings"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/filter"
 )
 
 var dir string
 
-func GetFiles(args []string, showDotFiles bool) ([]os.DirEntry, string) {
+func GetFiles(config config.Config) ([]os.DirEntry, string) {
+	args := config.Args
 	if len(args) > 0 {
 		dir = args[0] // Get the first non-flag argument passed to the program
 	} else {
 		dir = "." // Default to current directory if no non-flag argument is provided
 	}
 
-	files, err := os.ReadDir(dir)
+	files, err := List(dir, config)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error reading directory: %v\n", err)
 		os.Exit(1)
 	}
-	if !showDotFiles {
-		var filteredFiles []os.DirEntry
-		for _, file := range files {
-			if !strings.HasPrefix(file.Name(), ".") {
-				filteredFiles = append(filteredFiles, file)
+	return files, dir
+}
+
+// List returns the entries of dir that config says to list, in the order
+// it says to list them, for a flat listing of dir. With ShowDotEntries, the
+// . and .. entries are pinned to the start, or to the end when the order
+// is reversed, as with ls -ar.
+func List(dir string, config config.Config) ([]os.DirEntry, error) {
+	entries, err := ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries = Filter(entries, dir, config)
+	Sort(entries, config.SortKeys, config.SortReverse, config.DirsFirst)
+
+	if config.ShowDotEntries {
+		dots := Filter(dotEntries(dir), dir, config)
+		if config.SortReverse {
+			for i, j := 0, len(dots)-1; i < j; i, j = i+1, j-1 {
+				dots[i], dots[j] = dots[j], dots[i]
 			}
+			return append(entries, dots...), nil
 		}
-		files = filteredFiles
+		entries = append(dots, entries...)
 	}
+	return entries, nil
+}
 
-	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+// Filter returns the entries that config says to list, out of entries,
+// which are in dir. In the tree view, a directory that --match, --type or
+// --where leave out is still listed when anything below it is, so that
+// the tree shows where the listed entries are.
+func Filter(entries []os.DirEntry, dir string, config config.Config) []os.DirEntry {
+	relDir, err := filepath.Rel(config.Dir, dir)
+	if err != nil {
+		relDir = dir
+	}
+	relDir = filepath.ToSlash(relDir)
 
-	return files, dir
+	var filtered []os.DirEntry
+	for _, entry := range entries {
+		name := entry.Name()
+		if !config.ShowDotFiles && strings.HasPrefix(name, ".") {
+			continue
+		}
+		relPath := path.Join(relDir, name)
+		if filter.MatchAny(config.Ignore, relPath, entry.IsDir()) {
+			continue
+		}
+		if config.GitIgnore != nil && config.GitIgnore.Ignored(filepath.Join(dir, name), entry.IsDir()) {
+			continue
+		}
+		if !selected(entry, filepath.Join(dir, name), relPath, config) &&
+			!(config.Tree && entry.IsDir() && hasListedEntries(filepath.Join(dir, name), relPath, config)) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+// selected reports whether entry, which is at path and at relPath in the
+// listed directory, passes --match, --type and --where.
+func selected(entry os.DirEntry, path, relPath string, config config.Config) bool {
+	if len(config.Match) > 0 && !filter.MatchAny(config.Match, relPath, entry.IsDir()) {
+		return false
+	}
+	if config.Types != "" && strings.IndexByte(config.Types, filter.TypeLetter(entry.Type())) < 0 {
+		return false
+	}
+	if config.Where != nil {
+		info, err := entry.Info()
+		if err != nil || !config.Where.Match(path, info) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasListedEntries reports whether the tree view would list anything in
+// the directory dir, which is at relPath in the listed directory. Nothing
+// is listed in directories beyond config.MaxDepth. The answer is kept in
+// config.Listed, if set, so that each directory is only looked into once
+// however deep the tree is.
+func hasListedEntries(dir, relPath string, config config.Config) bool {
+	if listed, ok := config.Listed[dir]; ok {
+		return listed
+	}
+	listed := false
+	if config.MaxDepth == -1 || strings.Count(relPath, "/")+1 <= config.MaxDepth {
+		if entries, err := ReadDir(dir); err == nil {
+			listed = len(Filter(entries, dir, config)) > 0
+		}
+	}
+	if config.Listed != nil {
+		config.Listed[dir] = listed
+	}
+	return listed
+}
+
+// ReadDir returns the entries of the directory at path in the order the
+// file system lists them, for Sort to put in order.
+func ReadDir(path string) ([]os.DirEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.ReadDir(-1)
 }
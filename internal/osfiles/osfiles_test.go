@@ -0,0 +1,135 @@
+package osfiles
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/filter"
+)
+
+// treeLines walks dir as the tree view does and returns the paths it
+// lists, relative to dir.
+func treeLines(t *testing.T, dir string, config config.Config) []string {
+	t.Helper()
+	var lines []string
+	var walk func(path string, depth int)
+	walk = func(path string, depth int) {
+		if config.MaxDepth != -1 && depth > config.MaxDepth {
+			return
+		}
+		entries, err := ReadDir(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = Filter(entries, path, config)
+		Sort(entries, nil, false, false)
+		for _, entry := range entries {
+			child := filepath.Join(path, entry.Name())
+			rel, _ := filepath.Rel(dir, child)
+			lines = append(lines, filepath.ToSlash(rel))
+			if entry.IsDir() {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk(dir, 0)
+	return lines
+}
+
+func TestFilterPrunesTree(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/b/c/main.go", "a/notes.txt", "docs/big.log", "empty/sub/", "src/", "top.go"} {
+		path := filepath.Join(dir, name)
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	goFiles, err := filter.Parse("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	goFilesOrSrc, err := filter.Parse("*.go|src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs, err := filter.ParseExpr(`ext == "log"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subDir, err := filter.ParseExpr(`type == "d" && name == "sub"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config config.Config
+		want   string
+	}{
+		{
+			name: "no filters",
+			want: "a a/b a/b/c a/b/c/main.go a/notes.txt docs docs/big.log empty empty/sub src top.go",
+		},
+		{
+			name:   "match",
+			config: config.Config{Match: goFiles},
+			want:   "a a/b a/b/c a/b/c/main.go top.go",
+		},
+		{
+			// A directory that matches itself is listed, even if empty.
+			name:   "match a directory",
+			config: config.Config{Match: goFilesOrSrc},
+			want:   "a a/b a/b/c a/b/c/main.go src top.go",
+		},
+		{
+			name:   "type without directories",
+			config: config.Config{Types: "f"},
+			want:   "a a/b a/b/c a/b/c/main.go a/notes.txt docs docs/big.log top.go",
+		},
+		{
+			name:   "type with directories",
+			config: config.Config{Types: "d"},
+			want:   "a a/b a/b/c docs empty empty/sub src",
+		},
+		{
+			name:   "where",
+			config: config.Config{Where: logs},
+			want:   "docs docs/big.log",
+		},
+		{
+			// A directory that matches keeps the ones above it.
+			name:   "where on a directory",
+			config: config.Config{Where: subDir},
+			want:   "empty empty/sub",
+		},
+		{
+			// Entries beyond the depth limit do not keep a directory.
+			name:   "depth",
+			config: config.Config{Match: goFiles, MaxDepth: 2},
+			want:   "top.go",
+		},
+	}
+	for _, test := range tests {
+		test.config.Tree = true
+		test.config.Dir = dir
+		test.config.Listed = map[string]bool{}
+		if test.config.MaxDepth == 0 {
+			test.config.MaxDepth = -1
+		}
+		if got := strings.Join(treeLines(t, dir, test.config), " "); got != test.want {
+			t.Errorf("%s: tree = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package osfiles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SiirRandall/lsd-go/internal/filter"
+)
+
+// SortKeys lists the keys --sort accepts, in the order they are documented.
+var SortKeys = []string{"name", "size", "time", "atime", "ctime", "extension", "natural", "version", "inode", "none"}
+
+var sortKeyAliases = map[string]string{
+	"ext":     "extension",
+	"mtime":   "time",
+	"version": "natural",
+}
+
+// ParseSortKeys parses a comma-separated list of sort keys such as
+// "ext,size". Entries that compare equal on the first key are ordered by
+// the second, and so on, with the name as the final tie-breaker. "none"
+// keeps the directory's own order and cannot be combined with other keys.
+func ParseSortKeys(spec string) ([]string, error) {
+	var keys []string
+	for _, key := range strings.Split(spec, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if alias, ok := sortKeyAliases[key]; ok {
+			key = alias
+		}
+		if _, ok := compareBy[key]; !ok && key != "none" {
+			return nil, fmt.Errorf("unknown key %q: expected %s", key, strings.Join(SortKeys, ", "))
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) > 1 {
+		for _, key := range keys {
+			if key == "none" {
+				return nil, fmt.Errorf("\"none\" cannot be combined with other keys")
+			}
+		}
+	}
+	return keys, nil
+}
+
+// sortEntry is an entry being sorted, with its Lstat result fetched once.
+type sortEntry struct {
+	entry os.DirEntry
+	info  os.FileInfo // nil if it could not be read
+}
+
+// compareBy holds a comparison for every sort key, returning a negative
+// number if a sorts before b, a positive one if after and 0 on a tie. Like
+// ls, sizes and times sort largest and newest first.
+var compareBy = map[string]func(a, b sortEntry) int{
+	"name": func(a, b sortEntry) int {
+		return compareNames(a.entry.Name(), b.entry.Name())
+	},
+	"size": func(a, b sortEntry) int {
+		return -compareInts(size(a), size(b))
+	},
+	"time": func(a, b sortEntry) int {
+		return -modTime(a).Compare(modTime(b))
+	},
+	"atime": func(a, b sortEntry) int {
+		return -statTime(a, filter.AccessTime).Compare(statTime(b, filter.AccessTime))
+	},
+	"ctime": func(a, b sortEntry) int {
+		return -statTime(a, filter.ChangeTime).Compare(statTime(b, filter.ChangeTime))
+	},
+	"extension": func(a, b sortEntry) int {
+		return strings.Compare(extension(a.entry.Name()), extension(b.entry.Name()))
+	},
+	"natural": func(a, b sortEntry) int {
+		return CompareNatural(a.entry.Name(), b.entry.Name())
+	},
+	"inode": func(a, b sortEntry) int {
+		return compareInts(int64(inode(a)), int64(inode(b)))
+	},
+}
+
+// Sort orders entries by keys, as returned by ParseSortKeys; no keys means
+// by name. reverse flips the order of the keys, while dirsFirst puts
+// directories before everything else either way.
+func Sort(entries []os.DirEntry, keys []string, reverse, dirsFirst bool) {
+	if len(keys) == 0 {
+		keys = []string{"name"}
+	}
+	if len(keys) == 1 && keys[0] == "none" {
+		if dirsFirst {
+			sort.SliceStable(entries, func(i, j int) bool {
+				return entries[i].IsDir() && !entries[j].IsDir()
+			})
+		}
+		return
+	}
+	if keys[len(keys)-1] != "name" {
+		keys = append(keys[:len(keys):len(keys)], "name")
+	}
+
+	needInfo := false
+	for _, key := range keys {
+		needInfo = needInfo || (key != "name" && key != "extension" && key != "natural")
+	}
+	sorted := make([]sortEntry, len(entries))
+	for i, entry := range entries {
+		sorted[i].entry = entry
+		if needInfo {
+			sorted[i].info, _ = entry.Info()
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if dirsFirst && a.entry.IsDir() != b.entry.IsDir() {
+			return a.entry.IsDir()
+		}
+		for _, key := range keys {
+			if c := compareBy[key](a, b); c != 0 {
+				if reverse {
+					return c > 0
+				}
+				return c < 0
+			}
+		}
+		return false
+	})
+	for i := range sorted {
+		entries[i] = sorted[i].entry
+	}
+}
+
+// extension returns the lower-cased extension of name; a leading dot does
+// not start one, so ".bashrc" has none.
+func extension(name string) string {
+	return strings.ToLower(filepath.Ext(strings.TrimPrefix(name, ".")))
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func size(e sortEntry) int64 {
+	if e.info == nil {
+		return 0
+	}
+	return e.info.Size()
+}
+
+func modTime(e sortEntry) time.Time {
+	if e.info == nil {
+		return time.Time{}
+	}
+	return e.info.ModTime()
+}
+
+func statTime(e sortEntry, field func(os.FileInfo) time.Time) time.Time {
+	if e.info == nil {
+		return time.Time{}
+	}
+	return field(e.info)
+}
+
+func inode(e sortEntry) uint64 {
+	if e.info == nil {
+		return 0
+	}
+	if sys, ok := e.info.Sys().(*syscall.Stat_t); ok {
+		return sys.Ino
+	}
+	return 0
+}
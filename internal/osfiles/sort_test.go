@@ -0,0 +1,123 @@
+package osfiles
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeFile is a synthetic directory entry that is its own os.FileInfo.
+type fakeFile struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+func (f fakeFile) Name() string               { return f.name }
+func (f fakeFile) IsDir() bool                { return f.dir }
+func (f fakeFile) Type() os.FileMode          { return f.Mode().Type() }
+func (f fakeFile) Info() (os.FileInfo, error) { return f, nil }
+func (f fakeFile) Size() int64                { return f.size }
+func (f fakeFile) ModTime() time.Time         { return f.modTime }
+func (f fakeFile) Sys() interface{}           { return nil }
+func (f fakeFile) Mode() os.FileMode {
+	if f.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func TestParseSortKeys(t *testing.T) {
+	tests := []struct {
+		spec string
+		keys string
+		err  string
+	}{
+		{spec: "name", keys: "name"},
+		{spec: "ext,size", keys: "extension,size"},
+		{spec: " Size , MTIME ", keys: "size,time"},
+		{spec: "version", keys: "natural"},
+		{spec: "none", keys: "none"},
+		{spec: "size,none", err: `"none" cannot be combined with other keys`},
+		{spec: "none,none", err: `"none" cannot be combined with other keys`},
+		{spec: "sise", err: `unknown key "sise"`},
+		{spec: "name,", err: `unknown key ""`},
+		{spec: "", err: `unknown key ""`},
+	}
+	for _, test := range tests {
+		keys, err := ParseSortKeys(test.spec)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("ParseSortKeys(%q) error = %v, want %q", test.spec, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSortKeys(%q) failed: %v", test.spec, err)
+			continue
+		}
+		if got := strings.Join(keys, ","); got != test.keys {
+			t.Errorf("ParseSortKeys(%q) = %s, want %s", test.spec, got, test.keys)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	// The entries are listed in "directory order", which "none" keeps.
+	entries := []fakeFile{
+		{name: "b.txt", size: 10, modTime: now},
+		{name: "src", dir: true, size: 4096, modTime: now.Add(-time.Hour)},
+		{name: "a.go", size: 10, modTime: now.Add(-2 * time.Hour)},
+		{name: "c.go", size: 30, modTime: now},
+		{name: "doc", dir: true, size: 4096, modTime: now},
+		{name: "file10", size: 20, modTime: now.Add(-time.Hour)},
+		{name: "file2", size: 20, modTime: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		keys      string
+		reverse   bool
+		dirsFirst bool
+		want      string
+	}{
+		{"", false, false, "a.go b.txt c.go doc file10 file2 src"},
+		{"name", false, false, "a.go b.txt c.go doc file10 file2 src"},
+		{"name", true, false, "src file2 file10 doc c.go b.txt a.go"},
+		{"natural", false, false, "a.go b.txt c.go doc file2 file10 src"},
+		// Larger sizes first; ties go by name.
+		{"size", false, false, "doc src c.go file10 file2 a.go b.txt"},
+		// Each key breaks the ties of the one before it.
+		{"size,natural", false, false, "doc src c.go file2 file10 a.go b.txt"},
+		{"extension", false, false, "doc file10 file2 src a.go c.go b.txt"},
+		{"extension,size", false, false, "doc src file10 file2 c.go a.go b.txt"},
+		{"time,extension", false, false, "doc c.go b.txt file10 file2 src a.go"},
+		// Reversing flips every key, the name tie-breaker included.
+		{"size", true, false, "b.txt a.go file2 file10 c.go src doc"},
+		// Directories come first whether or not the order is reversed.
+		{"name", false, true, "doc src a.go b.txt c.go file10 file2"},
+		{"name", true, true, "src doc file2 file10 c.go b.txt a.go"},
+		{"size", true, true, "src doc b.txt a.go file2 file10 c.go"},
+		// "none" keeps the directory's order, apart from dirsFirst, and
+		// is not reversed.
+		{"none", false, false, "b.txt src a.go c.go doc file10 file2"},
+		{"none", true, false, "b.txt src a.go c.go doc file10 file2"},
+		{"none", false, true, "src doc b.txt a.go c.go file10 file2"},
+	}
+	for _, test := range tests {
+		var keys []string
+		if test.keys != "" {
+			keys = strings.Split(test.keys, ",")
+		}
+		sorted := make([]os.DirEntry, len(entries))
+		for i, entry := range entries {
+			sorted[i] = entry
+		}
+		Sort(sorted, keys, test.reverse, test.dirsFirst)
+		if got := strings.Join(Names(sorted), " "); got != test.want {
+			t.Errorf("Sort by %q, reverse %v, dirsFirst %v = %s, want %s", test.keys, test.reverse, test.dirsFirst, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package osfiles
+
+import (
+	"bufio"
+	"os"
+)
+
+// PrintNullTerminated writes names to standard output, each followed by a
+// NUL byte instead of a newline and without any styling, so they can be
+// passed safely to xargs -0.
+func PrintNullTerminated(names []string) {
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	for _, name := range names {
+		out.WriteString(name + "\x00")
+	}
+}
+
+// Names returns the names of entries.
+func Names(entries []os.DirEntry) []string {
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names
+}
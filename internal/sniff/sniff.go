@@ -0,0 +1,209 @@
+package sniff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+)
+
+// Class is the broad category of a file's content.
+type Class string
+
+const (
+	Unknown    Class = ""
+	Executable Class = "executable"
+	Script     Class = "script"
+	Image      Class = "image"
+	Archive    Class = "archive"
+	PDF        Class = "pdf"
+	Text       Class = "text"
+	Data       Class = "data"
+	Empty      Class = "empty"
+)
+
+// Kind describes what a file contains.
+type Kind struct {
+	Class       Class
+	Description string // e.g. "ELF binary", "python3 script", "PNG image"
+	Interpreter string // base name of a script's interpreter, e.g. "bash"
+}
+
+// headerSize bounds how much of each file is read. It covers the tar magic
+// at offset 257.
+const headerSize = 512
+
+type signature struct {
+	offset      int
+	magic       string
+	class       Class
+	description string
+}
+
+var signatures = []signature{
+	{0, "\x7fELF", Executable, "ELF binary"},
+	{0, "\xfe\xed\xfa\xce", Executable, "Mach-O binary"},
+	{0, "\xfe\xed\xfa\xcf", Executable, "Mach-O binary"},
+	{0, "\xce\xfa\xed\xfe", Executable, "Mach-O binary"},
+	{0, "\xcf\xfa\xed\xfe", Executable, "Mach-O binary"},
+	{0, "\x89PNG\r\n\x1a\n", Image, "PNG image"},
+	{0, "\xff\xd8\xff", Image, "JPEG image"},
+	{0, "GIF87a", Image, "GIF image"},
+	{0, "GIF89a", Image, "GIF image"},
+	{0, "II*\x00", Image, "TIFF image"},
+	{0, "MM\x00*", Image, "TIFF image"},
+	{0, "%PDF-", PDF, "PDF document"},
+	{0, "PK\x03\x04", Archive, "zip archive"},
+	{0, "PK\x05\x06", Archive, "zip archive"},
+	{0, "\x1f\x8b", Archive, "gzip archive"},
+	{0, "BZh", Archive, "bzip2 archive"},
+	{0, "\xfd7zXZ\x00", Archive, "xz archive"},
+	{0, "\x28\xb5\x2f\xfd", Archive, "zstd archive"},
+	{0, "7z\xbc\xaf\x27\x1c", Archive, "7-zip archive"},
+	{0, "Rar!\x1a\x07", Archive, "rar archive"},
+	{257, "ustar", Archive, "tar archive"},
+}
+
+// File classifies the file at path, whose Lstat result is info, by its
+// first bytes. Anything but a regular file, such as a directory, symlink,
+// FIFO or device, is never opened and yields an Unknown kind, as does a
+// file that cannot be read.
+func File(path string, info os.FileInfo) Kind {
+	if !info.Mode().IsRegular() {
+		return Kind{}
+	}
+	if info.Size() == 0 {
+		return Kind{Class: Empty, Description: "empty"}
+	}
+
+	// O_NONBLOCK keeps a file that was swapped for a FIFO since it was
+	// listed from blocking the open.
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return Kind{}
+	}
+	defer f.Close()
+	if stat, err := f.Stat(); err != nil || !stat.Mode().IsRegular() {
+		return Kind{}
+	}
+
+	header := make([]byte, headerSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return Kind{}
+	}
+	return Bytes(header[:n])
+}
+
+// Bytes classifies content, the leading bytes of a file.
+func Bytes(content []byte) Kind {
+	if len(content) == 0 {
+		return Kind{Class: Empty, Description: "empty"}
+	}
+	if bytes.HasPrefix(content, []byte("#!")) {
+		interpreter := interpreter(content)
+		description := "script"
+		if interpreter != "" {
+			description = interpreter + " script"
+		}
+		return Kind{Class: Script, Description: description, Interpreter: interpreter}
+	}
+	if isPE(content) {
+		return Kind{Class: Executable, Description: "PE binary"}
+	}
+	if kind, ok := cafebabe(content); ok {
+		return kind
+	}
+	if isWebP(content) {
+		return Kind{Class: Image, Description: "WebP image"}
+	}
+	for _, sig := range signatures {
+		end := sig.offset + len(sig.magic)
+		if len(content) >= end && string(content[sig.offset:end]) == sig.magic {
+			return Kind{Class: sig.class, Description: sig.description}
+		}
+	}
+	if isText(content) {
+		return Kind{Class: Text, Description: "text"}
+	}
+	return Kind{Class: Data, Description: "data"}
+}
+
+// interpreter returns the program named on a shebang line, looking through
+// "/usr/bin/env", e.g. "python3" for "#!/usr/bin/env -S python3 -u".
+func interpreter(content []byte) string {
+	line := string(content[2:])
+	if end := strings.IndexAny(line, "\r\n"); end >= 0 {
+		line = line[:end]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	program := filepath.Base(fields[0])
+	if program == "env" {
+		program = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				program = filepath.Base(field)
+				break
+			}
+		}
+	}
+	return program
+}
+
+// isPE reports whether content starts a PE binary. "MZ" alone is too
+// common at the start of text to go by, so the DOS header's e_lfanew field,
+// at 0x3C, must also point at the "PE\0\0" signature.
+func isPE(content []byte) bool {
+	if len(content) < 0x40 || !bytes.HasPrefix(content, []byte("MZ")) {
+		return false
+	}
+	offset := uint64(binary.LittleEndian.Uint32(content[0x3c:]))
+	return offset+4 <= uint64(len(content)) && string(content[offset:offset+4]) == "PE\x00\x00"
+}
+
+// cafebabe classifies content starting with 0xCAFEBABE, the magic of both
+// Mach-O universal binaries and Java class files. The big-endian uint32
+// that follows is the architecture count of a universal binary, which is
+// small, and the minor and major versions of a class file, where the major
+// version is at least 45.
+func cafebabe(content []byte) (Kind, bool) {
+	if len(content) < 8 || !bytes.HasPrefix(content, []byte("\xca\xfe\xba\xbe")) {
+		return Kind{}, false
+	}
+	switch n := binary.BigEndian.Uint32(content[4:]); {
+	case n >= 45:
+		return Kind{Class: Data, Description: "Java class file"}, true
+	case n > 0:
+		return Kind{Class: Executable, Description: "Mach-O universal binary"}, true
+	}
+	return Kind{}, false
+}
+
+// isWebP reports whether content starts a WebP image: a RIFF container,
+// whose size field is skipped, holding WEBP data.
+func isWebP(content []byte) bool {
+	return len(content) >= 12 && string(content[:4]) == "RIFF" && string(content[8:12]) == "WEBP"
+}
+
+// isText reports whether content looks like text: valid UTF-8 without NUL
+// bytes. A rune cut off by the end of the header is allowed.
+func isText(content []byte) bool {
+	if bytes.IndexByte(content, 0) >= 0 {
+		return false
+	}
+	for len(content) > 0 {
+		r, size := utf8.DecodeRune(content)
+		if r == utf8.RuneError && size == 1 {
+			return len(content) < utf8.UTFMax && !utf8.FullRune(content)
+		}
+		content = content[size:]
+	}
+	return true
+}
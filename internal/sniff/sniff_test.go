@@ -0,0 +1,129 @@
+package sniff
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// peHeader returns a DOS header whose e_lfanew points at offset, followed
+// by enough room for a PE signature there.
+func peHeader(offset uint32, signature string) []byte {
+	content := make([]byte, offset+64)
+	copy(content, "MZ")
+	binary.LittleEndian.PutUint32(content[0x3c:], offset)
+	copy(content[offset:], signature)
+	return content
+}
+
+func TestBytes(t *testing.T) {
+	tar := make([]byte, 300)
+	copy(tar, "file.txt")
+	copy(tar[257:], "ustar\x0000")
+	webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")
+
+	tests := []struct {
+		name        string
+		content     []byte
+		class       Class
+		description string
+	}{
+		{"empty", nil, Empty, "empty"},
+		{"ELF", []byte("\x7fELF\x02\x01\x01"), Executable, "ELF binary"},
+		{"Mach-O", []byte("\xcf\xfa\xed\xfe\x07\x00"), Executable, "Mach-O binary"},
+		{"PE", peHeader(0x80, "PE\x00\x00"), Executable, "PE binary"},
+		{"PE signature past the header", peHeader(0x80, "PE\x00\x00")[:0x82], Data, "data"},
+		{"MZ without PE signature", peHeader(0x80, "NE\x00\x00"), Data, "data"},
+		{"MZ text", []byte("MZ is how this note starts\n"), Text, "text"},
+		{"Mach-O universal", []byte("\xca\xfe\xba\xbe\x00\x00\x00\x02\x01\x00"), Executable, "Mach-O universal binary"},
+		{"Java class", []byte("\xca\xfe\xba\xbe\x00\x00\x00\x34\x00\x1d"), Data, "Java class file"}, // Java 8
+		{"Java class of the first version", []byte("\xca\xfe\xba\xbe\x00\x03\x00\x2d"), Data, "Java class file"},
+		{"Java class with a preview minor version", []byte("\xca\xfe\xba\xbe\xff\xff\x00\x41"), Data, "Java class file"},
+		{"PNG", []byte("\x89PNG\r\n\x1a\n\x00\x00"), Image, "PNG image"},
+		{"WebP", webp, Image, "WebP image"},
+		{"truncated WebP", webp[:10], Data, "data"},
+		{"WEBP without RIFF", []byte("XXXX\x00\x00\x00\x00WEBP"), Data, "data"},
+		{"PDF", []byte("%PDF-1.7\n"), PDF, "PDF document"},
+		{"zip", []byte("PK\x03\x04\x14\x00"), Archive, "zip archive"},
+		{"gzip", []byte("\x1f\x8b\x08\x00"), Archive, "gzip archive"},
+		{"tar", tar, Archive, "tar archive"},
+		{"text", []byte("hello, world\n"), Text, "text"},
+		{"UTF-8 text", []byte("grüße\n"), Text, "text"},
+		{"rune cut off at the end", []byte("gr\xc3"), Text, "text"},
+		{"invalid UTF-8", []byte("gr\xc3(ße"), Data, "data"},
+		{"NUL byte", []byte("abc\x00def"), Data, "data"},
+	}
+	for _, test := range tests {
+		got := Bytes(test.content)
+		if got.Class != test.class || got.Description != test.description {
+			t.Errorf("%s: Bytes = %q (%s), want %q (%s)", test.name, got.Class, got.Description, test.class, test.description)
+		}
+	}
+}
+
+func TestScriptInterpreter(t *testing.T) {
+	tests := []struct {
+		shebang     string
+		interpreter string
+		description string
+	}{
+		{"#!/bin/sh\n", "sh", "sh script"},
+		{"#!/bin/bash -e\r\necho", "bash", "bash script"},
+		{"#! /usr/bin/python3\n", "python3", "python3 script"},
+		{"#!/usr/bin/env node\n", "node", "node script"},
+		{"#!/usr/bin/env -S python3 -u\n", "python3", "python3 script"},
+		{"#!/usr/bin/env LANG=C perl\n", "perl", "perl script"},
+		{"#!/usr/bin/env\n", "", "script"},
+		{"#!\n", "", "script"},
+	}
+	for _, test := range tests {
+		got := Bytes([]byte(test.shebang))
+		if got.Class != Script || got.Interpreter != test.interpreter || got.Description != test.description {
+			t.Errorf("Bytes(%q) = %+v, want a %q script described as %q", test.shebang, got, test.interpreter, test.description)
+		}
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	script := write("script", "#!/bin/sh\n"+strings.Repeat("echo\n", 200))
+	empty := write("empty", "")
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(script, link); err != nil {
+		t.Fatal(err)
+	}
+	fifo := filepath.Join(dir, "fifo")
+	if err := syscall.Mkfifo(fifo, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		class Class
+	}{
+		{script, Script},
+		{empty, Empty},
+		{dir, Unknown},
+		{link, Unknown}, // symlinks are not followed
+		{fifo, Unknown}, // and FIFOs never opened
+	}
+	for _, test := range tests {
+		info, err := os.Lstat(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := File(test.path, info); got.Class != test.class {
+			t.Errorf("File(%s) = %q, want %q", filepath.Base(test.path), got.Class, test.class)
+		}
+	}
+}
@@ -1,14 +1,16 @@
 package stdls
 
 import (
+	"bufio"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/list"
+	"github.com/SiirRandall/lsd-go/internal/osfiles"
 	"github.com/SiirRandall/lsd-go/internal/style"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -56,28 +58,35 @@ func (m model) View() string {
 
 func StdLS(config config.Config) {
 	dir := config.Dir
-	files, err := os.ReadDir(dir)
+	files, err := osfiles.List(dir, config)
 	if err != nil {
 		fmt.Println("Error reading directory:", err)
 		os.Exit(1)
 	}
-	// Filter out dot files if the showdot flag is not set.
-	filteredFiles := []fs.DirEntry{}
-	for _, file := range files {
-		if config.ShowDotFiles || !strings.HasPrefix(file.Name(), ".") {
-			filteredFiles = append(filteredFiles, file)
-		}
+
+	if config.NullTerminate {
+		osfiles.PrintNullTerminated(osfiles.Names(files))
+		return
 	}
-	files = filteredFiles
 
-	sort.Slice(files, sorter(files, config))
+	// Like ls, fall back to one entry per line when the output is not a
+	// terminal, since there is no width to lay a grid out in.
+	if config.OnePerLine || !terminal.IsTerminal(int(os.Stdout.Fd())) {
+		printOnePerLine(dir, files, config)
+		return
+	}
 
 	width, _, _ := terminal.GetSize(int(os.Stdout.Fd()))
 
+	if config.Template != "" {
+		printTemplateGrid(dir, files, width, config)
+		return
+	}
+
 	maxFilenameLength := 0
 	for _, file := range files {
 		icon, _ := getIconAndColorForFileOrDir(dir, file.Name())
-		visualLength := visualWidth(file.Name()) + visualWidth(icon)
+		visualLength := visualWidth(classify(dir, file.Name(), config)) + visualWidth(icon)
 		if visualLength > maxFilenameLength {
 			maxFilenameLength = visualLength
 		}
@@ -87,20 +96,17 @@ func StdLS(config config.Config) {
 	initialColumnWidth := maxFilenameLength + columnSpacing
 	numColumns := width / initialColumnWidth
 
-	var grid [][]string
-
+	var names []string
 	for _, file := range files {
-		if len(grid) == 0 || len(grid[len(grid)-1]) >= (len(files)+numColumns-1)/numColumns {
-			grid = append(grid, []string{})
-		}
-		grid[len(grid)-1] = append(grid[len(grid)-1], file.Name())
+		names = append(names, file.Name())
 	}
+	grid := columnize(names, numColumns)
 
 	for col, column := range grid {
 		maxColWidth := 0
 		for _, filename := range column {
 			icon, _ := getIconAndColorForFileOrDir(dir, filename)
-			visualLength := visualWidth(filename) + visualWidth(icon)
+			visualLength := visualWidth(classify(dir, filename, config)) + visualWidth(icon)
 			if visualLength > maxColWidth {
 				maxColWidth = visualLength
 			}
@@ -108,7 +114,14 @@ func StdLS(config config.Config) {
 
 		for idx, filename := range column {
 			icon, style := getIconAndColorForFileOrDir(dir, filename)
-			paddedName := fmt.Sprintf("%-*s", maxColWidth, icon+filename)
+			name := classify(dir, filename, config)
+			if config.Hyperlinks {
+				// Pad outside the link so only the name is clickable.
+				padding := strings.Repeat(" ", maxColWidth-visualWidth(icon+name))
+				grid[col][idx] = hyperlink(dir, filename, style.Render(icon+name)) + padding
+				continue
+			}
+			paddedName := fmt.Sprintf("%-*s", maxColWidth, icon+name)
 			grid[col][idx] = style.Render(paddedName)
 		}
 	}
@@ -117,27 +130,104 @@ func StdLS(config config.Config) {
 	fmt.Println(m.View())
 }
 
-func sorter(files []fs.DirEntry, config config.Config) func(i, j int) bool {
-	return func(i, j int) bool {
-		file1 := files[i]
-		file2 := files[j]
+func printOnePerLine(dir string, files []fs.DirEntry, config config.Config) {
+	var tmpl *list.Template
+	if config.Template != "" {
+		var err error
+		tmpl, err = list.ParseTemplate(config.Template, config.NoColor)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(2)
+		}
+	}
 
-		if config.DirsFirst {
-			// If dirsFirst is enabled and one is a directory while the other isn't
-			if file1.IsDir() && !file2.IsDir() {
-				return true
-			} else if !file1.IsDir() && file2.IsDir() {
-				return false
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	for _, file := range files {
+		if tmpl == nil {
+			icon, style := getIconAndColorForFileOrDir(dir, file.Name())
+			name := style.Render(icon + classify(dir, file.Name(), config))
+			if config.Hyperlinks {
+				name = hyperlink(dir, file.Name(), name)
 			}
+			fmt.Fprintln(out, name)
+			continue
+		}
+		fileInfo, err := file.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
 		}
+		line, err := tmpl.Render(dir, fileInfo, getIconForFileOrDir(dir, file.Name()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(out, line)
+	}
+}
+
+// printTemplateGrid lays out the entries rendered through config.Template
+// in the same column grid as the default view.
+func printTemplateGrid(dir string, files []fs.DirEntry, width int, config config.Config) {
+	tmpl, err := list.ParseTemplate(config.Template, config.NoColor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+
+	var cells []string
+	maxCellWidth := 0
+	for _, file := range files {
+		fileInfo, err := file.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		cell, err := tmpl.Render(dir, fileInfo, getIconForFileOrDir(dir, file.Name()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		cells = append(cells, cell)
+		if cellWidth := visualWidth(cell); cellWidth > maxCellWidth {
+			maxCellWidth = cellWidth
+		}
+	}
 
-		// If sortReverse is enabled
-		if config.SortReverse {
-			return strings.ToLower(file2.Name()) < strings.ToLower(file1.Name())
+	columnSpacing := 2 // space between columns
+	grid := columnize(cells, width/(maxCellWidth+columnSpacing))
+	for col, column := range grid {
+		maxColWidth := 0
+		for _, cell := range column {
+			if cellWidth := visualWidth(cell); cellWidth > maxColWidth {
+				maxColWidth = cellWidth
+			}
+		}
+		for idx, cell := range column {
+			grid[col][idx] = cell + strings.Repeat(" ", maxColWidth-visualWidth(cell))
 		}
-		// Default to alphabetical order
-		return strings.ToLower(file1.Name()) < strings.ToLower(file2.Name())
 	}
+
+	m := model{grid: grid}
+	fmt.Println(m.View())
+}
+
+// columnize splits items into numColumns columns, filling each column from
+// top to bottom.
+func columnize(items []string, numColumns int) [][]string {
+	if numColumns < 1 {
+		numColumns = 1
+	}
+
+	var grid [][]string
+	for _, item := range items {
+		if len(grid) == 0 || len(grid[len(grid)-1]) >= (len(items)+numColumns-1)/numColumns {
+			grid = append(grid, []string{})
+		}
+		grid[len(grid)-1] = append(grid[len(grid)-1], item)
+	}
+	return grid
 }
 
 func isDir(baseDir, filename string) bool {
@@ -149,24 +239,55 @@ func isDir(baseDir, filename string) bool {
 }
 
 func getIconAndColorForFileOrDir(baseDir, filename string) (string, lipgloss.Style) {
-	// Check for directories
-	if isDir(baseDir, filename) {
-		if icon, ok := style.FileTypeIconMap[filename]; ok {
-			return icon.Icon, lipgloss.NewStyle().Foreground(lipgloss.Color(icon.Color))
+	icon := getIconForFileOrDir(baseDir, filename)
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(icon.Color))
+	if style.NameColors == nil && style.GitIgnored == nil {
+		return icon.Icon, nameStyle
+	}
+	path := filepath.Join(baseDir, filename)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return icon.Icon, nameStyle
+	}
+	if style.NameColors != nil {
+		if colorStyle, ok := style.NameColors.Style(path, info); ok {
+			nameStyle = colorStyle
 		}
-		return " ", lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF")) // Default directory icon
 	}
+	return icon.Icon, style.DimIgnored(nameStyle, path, info.IsDir())
+}
+
+func getIconForFileOrDir(baseDir, filename string) style.FileTypeIcon {
+	path := filepath.Join(baseDir, filename)
+	info, err := os.Lstat(path)
+	if err != nil {
+		info = nil
+	}
+	return style.EntryIcon(path, info)
+}
 
-	// Check for files by extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	if icon, ok := style.ExtToFileTypeIconMap[ext]; ok {
-		return icon.Icon, lipgloss.NewStyle().Foreground(lipgloss.Color(icon.Color))
+// classify appends the type indicator config.IndicatorStyle asks for to
+// filename.
+func classify(baseDir, filename string, config config.Config) string {
+	if config.IndicatorStyle == "" {
+		return filename
+	}
+	info, err := os.Lstat(filepath.Join(baseDir, filename))
+	if err != nil {
+		return filename
 	}
-	return " ", lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")) // Default file icon
+	return filename + osfiles.Indicator(info.Mode(), config.IndicatorStyle)
 }
 
+func hyperlink(dir, filename, text string) string {
+	return style.Hyperlink(text, filepath.Join(dir, filename))
+}
+
+// visualWidth returns the number of columns s takes up on screen. Escape
+// sequences, such as colors and OSC 8 hyperlinks, take up none.
 func visualWidth(s string) int {
 	width := 0
+	s = stripEscapes(s)
 	for _, r := range s {
 		if runewidth.RuneWidth(r) == 2 {
 			width += 2
@@ -176,3 +297,39 @@ func visualWidth(s string) int {
 	}
 	return width
 }
+
+// stripEscapes removes CSI (e.g. colors) and OSC (e.g. hyperlinks) escape
+// sequences from s.
+func stripEscapes(s string) string {
+	if !strings.ContainsRune(s, '\x1b') {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\x1b' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '[':
+			// CSI: parameters, then a final byte in the range 0x40-0x7E.
+			i += 2
+			for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+				i++
+			}
+		case ']':
+			// OSC: terminated by BEL or ST (ESC \).
+			i += 2
+			for i < len(s) && s[i] != '\a' && !(s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\') {
+				i++
+			}
+			if i < len(s) && s[i] == '\x1b' {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	return b.String()
+}
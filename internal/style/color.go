@@ -0,0 +1,37 @@
+package style
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// SetColorMode applies the --color setting to every lipgloss style and
+// reports whether colors are enabled.
+//
+// "auto" colors output only when stdout is a terminal, honoring NO_COLOR and
+// CLICOLOR_FORCE. "always" colors output even when it is piped. "never"
+// turns colors off. When colors are on, they are downgraded to what the
+// terminal supports according to TERM and COLORTERM, e.g. truecolor to 256
+// or 16 colors.
+func SetColorMode(mode string) (bool, error) {
+	var profile termenv.Profile
+	switch mode {
+	case "auto":
+		profile = termenv.NewOutput(os.Stdout).EnvColorProfile()
+	case "always":
+		profile = termenv.NewOutput(os.Stdout, termenv.WithTTY(true)).ColorProfile()
+		if profile == termenv.Ascii {
+			profile = termenv.ANSI
+		}
+	case "never":
+		profile = termenv.Ascii
+	default:
+		return false, fmt.Errorf("invalid value %q for --color: expected auto, always or never", mode)
+	}
+
+	lipgloss.SetColorProfile(profile)
+	return profile != termenv.Ascii, nil
+}
@@ -0,0 +1,66 @@
+package style
+
+import (
+	"os"
+	"strings"
+
+	"github.com/SiirRandall/lsd-go/internal/sniff"
+)
+
+// SniffContent makes icons depend on what files contain, as recognized by
+// the sniff package, and not only on their names.
+var SniffContent = false
+
+// interpreterExtensions maps script interpreters to the extension whose
+// icon their scripts get.
+var interpreterExtensions = map[string]string{
+	"python": ".py",
+	"node":   ".js",
+	"ruby":   ".rb",
+	"perl":   ".pl",
+	"go":     ".go",
+	"rust":   ".rs",
+}
+
+var (
+	binaryIcon = FileTypeIcon{Icon: " "}
+	shellIcon  = FileTypeIcon{Icon: " "}
+)
+
+// SniffedIcon returns the icon for the regular file at path, whose Lstat
+// result is info, based on its content. It reports false when SniffContent
+// is off or the content does not call for a particular icon, in which case
+// the name decides.
+func SniffedIcon(path string, info os.FileInfo) (FileTypeIcon, IconKind, bool) {
+	if !SniffContent || info == nil {
+		return FileTypeIcon{}, FileIcon, false
+	}
+
+	kind := sniff.File(path, info)
+	executable := info.Mode().Perm()&0111 != 0
+	switch kind.Class {
+	case sniff.Executable:
+		return binaryIcon.WithColor(Current.Executable), ExecutableIcon, true
+	case sniff.Script:
+		icon := shellIcon
+		// Strip versions, so "python3.11" is treated like "python".
+		interpreter := strings.TrimRight(kind.Interpreter, "0123456789.")
+		if ext, ok := interpreterExtensions[interpreter]; ok {
+			if extIcon, ok := ExtToFileTypeIconMap[ext]; ok {
+				icon = extIcon
+			}
+		}
+		if executable {
+			icon.Color = Current.Executable
+			return icon, ExecutableIcon, true
+		}
+		return icon.WithColor(Current.File), FileIcon, true
+	case sniff.Image:
+		return ExtToFileTypeIconMap[".png"].WithColor(Current.File), FileIcon, true
+	case sniff.Archive:
+		return ExtToFileTypeIconMap[".zip"].WithColor(Current.File), FileIcon, true
+	case sniff.PDF:
+		return ExtToFileTypeIconMap[".pdf"].WithColor(Current.File), FileIcon, true
+	}
+	return FileTypeIcon{}, FileIcon, false
+}
@@ -0,0 +1,16 @@
+package style
+
+import "github.com/charmbracelet/lipgloss"
+
+// GitIgnored, if set, reports whether git ignores the entry at path. The
+// names of such entries are dimmed.
+var GitIgnored func(path string, isDir bool) bool
+
+// DimIgnored returns s, made faint if the entry at path is ignored by git
+// and GitIgnored is set.
+func DimIgnored(s lipgloss.Style, path string, isDir bool) lipgloss.Style {
+	if GitIgnored != nil && GitIgnored(path, isDir) {
+		return s.Copy().Faint(true)
+	}
+	return s
+}
@@ -0,0 +1,21 @@
+package style
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+var hostname, _ = os.Hostname()
+
+// Hyperlink wraps text in an OSC 8 escape sequence that links to path, so
+// terminals that support it open the file on click. The sequence itself
+// takes up no columns on screen.
+func Hyperlink(text, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return text
+	}
+	link := url.URL{Scheme: "file", Host: hostname, Path: abs}
+	return "\x1b]8;;" + link.String() + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
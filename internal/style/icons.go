@@ -0,0 +1,261 @@
+package style
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileNameIconMap holds icons for files matched by their exact name.
+var FileNameIconMap = map[string]FileTypeIcon{
+	"Makefile":   {" ", "icon-build"},
+	"Dockerfile": {" ", "icon-docker"},
+	"go.mod":     {" ", "icon-go"},
+	"go.sum":     {" ", "icon-go"},
+	".gitignore": {" ", "icon-git"},
+	"LICENSE":    {" ", "icon-license"},
+}
+
+// GlobIcon is an icon for files whose name matches Pattern, using the
+// syntax of filepath.Match.
+type GlobIcon struct {
+	Pattern string
+	Icon    FileTypeIcon
+}
+
+// GlobIcons are tried in order, so earlier patterns win.
+var GlobIcons = []GlobIcon{
+	{".env*", FileTypeIcon{" ", "icon-env"}},
+}
+
+// LookupIcon finds the icon for an entry called name. Directories are
+// matched by name against FileTypeIconMap. Files are matched, in this
+// order, by exact name against FileNameIconMap, by GlobIcons, and by
+// extension against ExtToFileTypeIconMap, trying compound extensions such
+// as ".tar.gz" before ".gz". Extensions are matched case-insensitively.
+func LookupIcon(name string, isDir bool) (FileTypeIcon, bool) {
+	if isDir {
+		icon, ok := FileTypeIconMap[name]
+		return icon, ok
+	}
+
+	if icon, ok := FileNameIconMap[name]; ok {
+		return icon, true
+	}
+	for _, glob := range GlobIcons {
+		if matched, _ := filepath.Match(glob.Pattern, name); matched {
+			return glob.Icon, true
+		}
+	}
+
+	lower := strings.ToLower(name)
+	for i := 0; i < len(lower); i++ {
+		if lower[i] != '.' {
+			continue
+		}
+		if icon, ok := ExtToFileTypeIconMap[lower[i:]]; ok {
+			return icon, true
+		}
+	}
+	return FileTypeIcon{}, false
+}
+
+// Icons for entries that no map or sniffed content gives one to.
+var (
+	symlinkIcon    = FileTypeIcon{Icon: " "}
+	directoryIcon  = FileTypeIcon{Icon: " "}
+	executableIcon = FileTypeIcon{Icon: " "}
+	fileIcon       = FileTypeIcon{Icon: " "}
+)
+
+// EntryIcon returns the icon for the entry at path, whose Lstat result is
+// info, as every view shows it. Symlinks get the symlink icon and
+// directories their icon by name. Files get an icon from their content
+// when SniffContent is on, the executable icon when it is off and an
+// execute bit is set, and otherwise an icon by name. info may be nil, in
+// which case the entry is looked up as a file by name.
+func EntryIcon(path string, info os.FileInfo) FileTypeIcon {
+	name := filepath.Base(path)
+	switch {
+	case info == nil:
+		// Look the entry up by name below.
+	case info.Mode()&os.ModeSymlink != 0:
+		return Themed(symlinkIcon.WithColor(Current.Symlink), SymlinkIcon)
+	case info.IsDir():
+		return DirIcon(info.Name())
+	default:
+		if icon, kind, ok := SniffedIcon(path, info); ok {
+			return Themed(icon, kind)
+		}
+		if !SniffContent && info.Mode().Perm()&0111 != 0 {
+			return Themed(executableIcon.WithColor(Current.Executable), ExecutableIcon)
+		}
+	}
+
+	icon, ok := LookupIcon(name, false)
+	if !ok {
+		icon = fileIcon
+	}
+	return Themed(icon.WithColor(Current.File), FileIcon)
+}
+
+// DirIcon returns the icon for a directory called name.
+func DirIcon(name string) FileTypeIcon {
+	icon, ok := LookupIcon(name, true)
+	if !ok {
+		icon = directoryIcon
+	}
+	return Themed(icon.WithColor(Current.Directory), DirectoryIcon)
+}
+
+// IconsFilePath returns the default location of the icons file,
+// next to the configuration file.
+func IconsFilePath(configPath string) string {
+	if configPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(configPath), "icons")
+}
+
+// LoadIcons adds the mappings from the icons file at path to the built-in
+// ones, overriding them where they overlap. A missing file is not an error.
+//
+// The file is split into [directory], [name], [glob] and [extension]
+// sections, each holding "key = ICON [COLOR]" lines. The icon is a
+// single glyph, and the color is either #rrggbb or a theme key such as
+// icon-go; leaving it out uses the theme's directory or file color.
+//
+//	[name]
+//	Justfile =  #6d8086
+//
+//	[glob]
+//	*.test.go =  #00ff00
+func LoadIcons(path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	section := ""
+	var globs []GlobIcon
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			switch section {
+			case "directory", "name", "glob", "extension":
+			default:
+				return fmt.Errorf("%s:%d: unknown section %q", path, lineNum, section)
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return fmt.Errorf("%s:%d: expected key = icon [color]", path, lineNum)
+		}
+		key = strings.TrimSpace(key)
+		fields := strings.Fields(value)
+		if key == "" || len(fields) == 0 || len(fields) > 2 {
+			return fmt.Errorf("%s:%d: expected key = icon [color]", path, lineNum)
+		}
+		icon := FileTypeIcon{Icon: fields[0] + " "}
+		if len(fields) == 2 {
+			icon.Color = fields[1]
+		}
+
+		switch section {
+		case "directory":
+			FileTypeIconMap[key] = icon
+		case "name":
+			FileNameIconMap[key] = icon
+		case "glob":
+			if _, err := filepath.Match(key, ""); err != nil {
+				return fmt.Errorf("%s:%d: invalid glob %q: %v", path, lineNum, key, err)
+			}
+			globs = append(globs, GlobIcon{Pattern: key, Icon: icon})
+		case "extension":
+			if !strings.HasPrefix(key, ".") {
+				key = "." + key
+			}
+			ExtToFileTypeIconMap[strings.ToLower(key)] = icon
+		default:
+			return fmt.Errorf("%s:%d: mapping outside of a section", path, lineNum)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// User globs take priority over the built-in ones.
+	GlobIcons = append(globs, GlobIcons...)
+	return nil
+}
+
+// IconKind is the broad kind of entry an icon is shown for. The plain-text
+// icon themes have one symbol per kind.
+type IconKind int
+
+const (
+	FileIcon IconKind = iota
+	DirectoryIcon
+	SymlinkIcon
+	ExecutableIcon
+)
+
+// ShowIcons turns icons on or off in every view.
+var ShowIcons = true
+
+// IconTheme selects the glyphs icons are drawn with: "nerd" uses the Nerd
+// Font glyphs from the icon maps, while "unicode" and "ascii" fall back to
+// a symbol per IconKind for terminals without a patched font.
+var IconTheme = "nerd"
+
+var plainIcons = map[string]map[IconKind]string{
+	"unicode": {
+		FileIcon:       "📄",
+		DirectoryIcon:  "📂",
+		SymlinkIcon:    "🔗",
+		ExecutableIcon: "⚡",
+	},
+	"ascii": {
+		FileIcon:       "-",
+		DirectoryIcon:  "d",
+		SymlinkIcon:    "l",
+		ExecutableIcon: "x",
+	},
+}
+
+// Themed returns icon as it should be displayed for an entry of the given
+// kind under ShowIcons and IconTheme. The color is kept in every case; the
+// icon is empty when icons are turned off.
+func Themed(icon FileTypeIcon, kind IconKind) FileTypeIcon {
+	if !ShowIcons {
+		icon.Icon = ""
+		return icon
+	}
+	if glyphs, ok := plainIcons[IconTheme]; ok {
+		icon.Icon = glyphs[kind] + " "
+	}
+	return icon
+}
+
+// ValidIconTheme reports whether name is a known icon theme.
+func ValidIconTheme(name string) bool {
+	_, plain := plainIcons[name]
+	return plain || name == "nerd"
+}
@@ -0,0 +1,137 @@
+package style
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withIcons replaces the icon maps with the given ones for the rest of the
+// test.
+func withIcons(t *testing.T, dirs, names, exts map[string]FileTypeIcon, globs []GlobIcon) {
+	t.Helper()
+	savedDirs, savedNames, savedExts, savedGlobs := FileTypeIconMap, FileNameIconMap, ExtToFileTypeIconMap, GlobIcons
+	t.Cleanup(func() {
+		FileTypeIconMap, FileNameIconMap, ExtToFileTypeIconMap, GlobIcons = savedDirs, savedNames, savedExts, savedGlobs
+	})
+	FileTypeIconMap, FileNameIconMap, ExtToFileTypeIconMap, GlobIcons = dirs, names, exts, globs
+}
+
+func TestLookupIcon(t *testing.T) {
+	withIcons(t,
+		map[string]FileTypeIcon{"src": {"D ", ""}},
+		map[string]FileTypeIcon{"Makefile": {"N ", ""}, "notes.tar.gz": {"N ", ""}},
+		map[string]FileTypeIcon{".gz": {"gz ", ""}, ".tar.gz": {"tgz ", ""}, ".go": {"go ", ""}},
+		[]GlobIcon{
+			{"*_test.go", FileTypeIcon{"T ", ""}},
+			{"*.go", FileTypeIcon{"G ", ""}},
+		})
+
+	tests := []struct {
+		name  string
+		isDir bool
+		icon  string // empty when there is no match
+	}{
+		{"src", true, "D "},
+		{"Makefile", true, ""}, // directories only use the directory map
+		{"src", false, ""},
+		{"Makefile", false, "N "},
+		{"makefile", false, ""}, // names are case-sensitive
+		// An exact name beats a glob and an extension.
+		{"notes.tar.gz", false, "N "},
+		// A glob beats an extension, and earlier globs win.
+		{"main_test.go", false, "T "},
+		{"main.go", false, "G "},
+		// Compound extensions are tried before simple ones.
+		{"backup.tar.gz", false, "tgz "},
+		{"backup.gz", false, "gz "},
+		{"BACKUP.TAR.GZ", false, "tgz "}, // extensions are case-insensitive
+		{"a.b.gz", false, "gz "},
+		{"README", false, ""},
+	}
+	for _, test := range tests {
+		icon, ok := LookupIcon(test.name, test.isDir)
+		if ok != (test.icon != "") || icon.Icon != test.icon {
+			t.Errorf("LookupIcon(%q, %v) = %q, %v, want %q", test.name, test.isDir, icon.Icon, ok, test.icon)
+		}
+	}
+}
+
+func TestLoadIcons(t *testing.T) {
+	withIcons(t,
+		map[string]FileTypeIcon{},
+		map[string]FileTypeIcon{"Makefile": {"N ", "icon-build"}},
+		map[string]FileTypeIcon{".go": {"go ", "icon-go"}},
+		[]GlobIcon{{"*.go", FileTypeIcon{"G ", ""}}})
+
+	path := filepath.Join(t.TempDir(), "icons")
+	text := `# comment
+[directory]
+src = D
+
+  [ name ]
+Makefile = M #6d8086
+
+[glob]
+*_test.go = T icon-go
+
+[extension]
+rs = R
+.Tar.GZ = Z
+`
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadIcons(path); err != nil {
+		t.Fatalf("LoadIcons failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		isDir bool
+		icon  FileTypeIcon
+	}{
+		{"src", true, FileTypeIcon{"D ", ""}},
+		{"Makefile", false, FileTypeIcon{"M ", "#6d8086"}}, // overrides the built-in
+		{"main_test.go", false, FileTypeIcon{"T ", "icon-go"}},
+		{"main.go", false, FileTypeIcon{"G ", ""}}, // the built-in glob is kept, after the user's
+		{"lib.rs", false, FileTypeIcon{"R ", ""}},  // a leading dot is added
+		{"x.tar.gz", false, FileTypeIcon{"Z ", ""}},
+	}
+	for _, test := range tests {
+		if icon, _ := LookupIcon(test.name, test.isDir); icon != test.icon {
+			t.Errorf("after LoadIcons, LookupIcon(%q) = %+v, want %+v", test.name, icon, test.icon)
+		}
+	}
+}
+
+func TestLoadIconsErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{"[names]\n", `:1: unknown section "names"`},
+		{"# comment\nMakefile = M\n", ":2: mapping outside of a section"},
+		{"[name]\nMakefile\n", ":2: expected key = icon [color]"},
+		{"[name]\n= M\n", ":2: expected key = icon [color]"},
+		{"[name]\nMakefile =\n", ":2: expected key = icon [color]"},
+		{"[name]\nMakefile = M #fff extra\n", ":2: expected key = icon [color]"},
+		{"[glob]\n[a- = G\n", `:2: invalid glob "[a-"`},
+	}
+	for _, test := range tests {
+		withIcons(t, map[string]FileTypeIcon{}, map[string]FileTypeIcon{}, map[string]FileTypeIcon{}, nil)
+		path := filepath.Join(t.TempDir(), "icons")
+		if err := os.WriteFile(path, []byte(test.text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := LoadIcons(path)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("LoadIcons(%q) error = %v, want %q", test.text, err, test.err)
+		}
+	}
+
+	if err := LoadIcons(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("LoadIcons of a missing file failed: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package style
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LSColors is a parsed LS_COLORS database, as written by dircolors.
+type LSColors struct {
+	types      map[string]lipgloss.Style
+	patterns   map[string]lipgloss.Style // lower-cased suffixes such as ".tar.gz"
+	linkTarget bool                      // "ln=target": color links like what they point to
+}
+
+// NameColors is the LS_COLORS database file names are colored from, or nil
+// to use the theme and icon colors.
+var NameColors *LSColors
+
+// ParseLSColors parses the value of LS_COLORS, such as
+// "di=01;34:ln=target:*.tar.gz=38;5;196". Entries it cannot make sense of
+// are skipped, like GNU ls does.
+func ParseLSColors(value string) *LSColors {
+	colors := &LSColors{types: map[string]lipgloss.Style{}, patterns: map[string]lipgloss.Style{}}
+	for _, entry := range strings.Split(value, ":") {
+		key, codes, found := strings.Cut(entry, "=")
+		if !found || key == "" {
+			continue
+		}
+		if key == "ln" && codes == "target" {
+			colors.linkTarget = true
+			continue
+		}
+		style, err := sgrStyle(codes)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(key, "*") {
+			colors.patterns[strings.ToLower(key[1:])] = style
+		} else {
+			colors.types[key] = style
+		}
+	}
+	return colors
+}
+
+// Style returns the style for the entry at path, whose Lstat result is
+// info. It reports false if the database has nothing for the entry.
+func (c *LSColors) Style(path string, info os.FileInfo) (lipgloss.Style, bool) {
+	mode := info.Mode()
+	if mode&os.ModeSymlink != 0 {
+		target, err := os.Stat(path)
+		if err != nil {
+			return c.typeStyle("or", "ln")
+		}
+		if c.linkTarget {
+			return c.Style(path, target)
+		}
+		return c.typeStyle("ln")
+	}
+
+	switch {
+	case mode.IsDir():
+		sticky := mode&os.ModeSticky != 0
+		otherWritable := mode.Perm()&0002 != 0
+		switch {
+		case sticky && otherWritable:
+			return c.typeStyle("tw", "ow", "st", "di")
+		case otherWritable:
+			return c.typeStyle("ow", "di")
+		case sticky:
+			return c.typeStyle("st", "di")
+		}
+		return c.typeStyle("di")
+	case mode&os.ModeNamedPipe != 0:
+		return c.typeStyle("pi")
+	case mode&os.ModeSocket != 0:
+		return c.typeStyle("so")
+	case mode&os.ModeCharDevice != 0:
+		return c.typeStyle("cd")
+	case mode&os.ModeDevice != 0:
+		return c.typeStyle("bd")
+	}
+	// A file that is both setuid and setgid falls back from su to sg.
+	if mode&os.ModeSetuid != 0 {
+		if style, ok := c.typeStyle("su"); ok {
+			return style, ok
+		}
+	}
+	if mode&os.ModeSetgid != 0 {
+		if style, ok := c.typeStyle("sg"); ok {
+			return style, ok
+		}
+	}
+	if mode.Perm()&0111 != 0 {
+		if style, ok := c.typeStyle("ex"); ok {
+			return style, ok
+		}
+	}
+
+	if style, ok := c.patternStyle(info.Name()); ok {
+		return style, ok
+	}
+	return c.typeStyle("fi")
+}
+
+// MissingStyle returns the style for a symlink target that does not exist.
+func (c *LSColors) MissingStyle() (lipgloss.Style, bool) {
+	return c.typeStyle("mi", "or")
+}
+
+// typeStyle returns the style of the first of keys that is set.
+func (c *LSColors) typeStyle(keys ...string) (lipgloss.Style, bool) {
+	for _, key := range keys {
+		if style, ok := c.types[key]; ok {
+			return style, true
+		}
+	}
+	return lipgloss.Style{}, false
+}
+
+// patternStyle matches name against the "*suffix" entries, preferring the
+// longest matching suffix so that "*.tar.gz" beats "*.gz".
+func (c *LSColors) patternStyle(name string) (lipgloss.Style, bool) {
+	name = strings.ToLower(name)
+	for i := 0; i < len(name); i++ {
+		if style, ok := c.patterns[name[i:]]; ok {
+			return style, true
+		}
+	}
+	return lipgloss.Style{}, false
+}
+
+// sgrStyle converts a list of SGR parameters, such as "01;38;5;208", into
+// a lipgloss style. 256-color ("38;5;n") and truecolor ("38;2;r;g;b")
+// sequences are supported for both foreground and background.
+func sgrStyle(codes string) (lipgloss.Style, error) {
+	style := lipgloss.NewStyle()
+	var params []int
+	for _, code := range strings.Split(codes, ";") {
+		if code == "" {
+			params = append(params, 0)
+			continue
+		}
+		n, err := strconv.Atoi(code)
+		if err != nil {
+			return style, fmt.Errorf("invalid SGR parameter %q", code)
+		}
+		params = append(params, n)
+	}
+
+	for i := 0; i < len(params); i++ {
+		switch p := params[i]; {
+		case p == 0:
+			style = lipgloss.NewStyle()
+		case p == 1:
+			style = style.Bold(true)
+		case p == 2:
+			style = style.Faint(true)
+		case p == 3:
+			style = style.Italic(true)
+		case p == 4:
+			style = style.Underline(true)
+		case p == 5 || p == 6:
+			style = style.Blink(true)
+		case p == 7:
+			style = style.Reverse(true)
+		case p == 9:
+			style = style.Strikethrough(true)
+		case p >= 30 && p <= 37:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(p - 30)))
+		case p >= 90 && p <= 97:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(p - 90 + 8)))
+		case p >= 40 && p <= 47:
+			style = style.Background(lipgloss.Color(strconv.Itoa(p - 40)))
+		case p >= 100 && p <= 107:
+			style = style.Background(lipgloss.Color(strconv.Itoa(p - 100 + 8)))
+		case p == 38 || p == 48:
+			color, consumed, err := extendedColor(params[i+1:])
+			if err != nil {
+				return style, err
+			}
+			i += consumed
+			if p == 38 {
+				style = style.Foreground(color)
+			} else {
+				style = style.Background(color)
+			}
+		}
+	}
+	return style, nil
+}
+
+// extendedColor parses the parameters following a 38 or 48, returning the
+// color and how many parameters it used.
+func extendedColor(params []int) (lipgloss.Color, int, error) {
+	if len(params) >= 2 && params[0] == 5 {
+		return lipgloss.Color(strconv.Itoa(params[1])), 2, nil
+	}
+	if len(params) >= 4 && params[0] == 2 {
+		return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", params[1]&0xff, params[2]&0xff, params[3]&0xff)), 4, nil
+	}
+	return "", 0, fmt.Errorf("invalid extended color")
+}
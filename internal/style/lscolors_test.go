@@ -0,0 +1,160 @@
+package style
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSGRStyle(t *testing.T) {
+	tests := []struct {
+		codes      string
+		foreground lipgloss.TerminalColor
+		background lipgloss.TerminalColor
+		bold       bool
+		wantErr    bool
+	}{
+		{codes: "01;34", foreground: lipgloss.Color("4"), bold: true},
+		{codes: "1;94", foreground: lipgloss.Color("12"), bold: true},
+		{codes: "30;41", foreground: lipgloss.Color("0"), background: lipgloss.Color("1")},
+		{codes: "38;5;208", foreground: lipgloss.Color("208")},
+		{codes: "48;5;17", background: lipgloss.Color("17")},
+		{codes: "38;2;255;0;128", foreground: lipgloss.Color("#ff0080")},
+		{codes: "01;38;5;196;48;2;0;0;0", foreground: lipgloss.Color("196"), background: lipgloss.Color("#000000"), bold: true},
+		// A reset drops everything before it.
+		{codes: "01;34;0;32", foreground: lipgloss.Color("2")},
+		{codes: "01;;32", foreground: lipgloss.Color("2")},
+		// Truncated extended colors are rejected instead of reading past
+		// the end of the sequence.
+		{codes: "38;5", wantErr: true},
+		{codes: "38", wantErr: true},
+		{codes: "48;2;1;2", wantErr: true},
+		{codes: "38;7;1", wantErr: true},
+		{codes: "01;xx", wantErr: true},
+	}
+	for _, test := range tests {
+		style, err := sgrStyle(test.codes)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("sgrStyle(%q) succeeded, want an error", test.codes)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("sgrStyle(%q) failed: %v", test.codes, err)
+			continue
+		}
+		if test.foreground == nil {
+			test.foreground = lipgloss.NoColor{}
+		}
+		if test.background == nil {
+			test.background = lipgloss.NoColor{}
+		}
+		if got := style.GetForeground(); got != test.foreground {
+			t.Errorf("sgrStyle(%q) foreground = %v, want %v", test.codes, got, test.foreground)
+		}
+		if got := style.GetBackground(); got != test.background {
+			t.Errorf("sgrStyle(%q) background = %v, want %v", test.codes, got, test.background)
+		}
+		if got := style.GetBold(); got != test.bold {
+			t.Errorf("sgrStyle(%q) bold = %v, want %v", test.codes, got, test.bold)
+		}
+	}
+}
+
+func TestParseLSColorsSkipsInvalidEntries(t *testing.T) {
+	colors := ParseLSColors("di=01;34:ex=38;5:*.GZ=31:bogus:=32:ln=target")
+	if _, ok := colors.types["di"]; !ok {
+		t.Error("di entry was dropped")
+	}
+	if _, ok := colors.types["ex"]; ok {
+		t.Error("truncated ex entry was kept")
+	}
+	if _, ok := colors.patterns[".gz"]; !ok {
+		t.Error("pattern keys are not lower-cased")
+	}
+	if !colors.linkTarget {
+		t.Error("ln=target was not recognized")
+	}
+}
+
+func TestLSColorsStyle(t *testing.T) {
+	dir := t.TempDir()
+	create := func(name string, perm os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, perm); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	create("archive.tar.gz", 0644)
+	create("notes.gz", 0644)
+	create("README", 0644)
+	create("run.sh", 0755)
+	for name, mode := range map[string]os.FileMode{
+		"setuid": os.ModeSetuid,
+		"setgid": os.ModeSetgid,
+		"both":   os.ModeSetuid | os.ModeSetgid,
+	} {
+		if err := os.Chmod(create(name, 0755), mode|0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("run.sh", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("missing", filepath.Join(dir, "orphan")); err != nil {
+		t.Fatal(err)
+	}
+
+	const database = "di=34:ex=32:ln=36:or=31:fi=37:*.gz=35:*.tar.gz=33"
+	tests := []struct {
+		name       string
+		database   string
+		foreground lipgloss.TerminalColor
+	}{
+		{"sub", database, lipgloss.Color("4")},
+		{"run.sh", database, lipgloss.Color("2")},
+		{"archive.tar.gz", database, lipgloss.Color("3")}, // longest suffix wins
+		{"notes.gz", database, lipgloss.Color("5")},
+		{"README", database, lipgloss.Color("7")},
+		{"link", database, lipgloss.Color("6")},
+		{"orphan", database, lipgloss.Color("1")},
+		{"link", "ln=target:ex=32", lipgloss.Color("2")},
+		{"setuid", "su=31:sg=33:ex=32", lipgloss.Color("1")},
+		{"setgid", "su=31:sg=33:ex=32", lipgloss.Color("3")},
+		{"setuid", "sg=33:ex=32", lipgloss.Color("2")},
+		// Setuid and setgid falls through su, then sg, then ex.
+		{"both", "su=31:sg=33:ex=32", lipgloss.Color("1")},
+		{"both", "sg=33:ex=32", lipgloss.Color("3")},
+		{"both", "ex=32", lipgloss.Color("2")},
+	}
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		style, ok := ParseLSColors(test.database).Style(path, info)
+		if !ok {
+			t.Errorf("%s with %q: no style", test.name, test.database)
+			continue
+		}
+		if got := style.GetForeground(); got != test.foreground {
+			t.Errorf("%s with %q: foreground = %v, want %v", test.name, test.database, got, test.foreground)
+		}
+	}
+
+	info, err := os.Lstat(filepath.Join(dir, "README"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ParseLSColors("di=34").Style(filepath.Join(dir, "README"), info); ok {
+		t.Error("a file matched a database without fi or patterns")
+	}
+}
@@ -2,30 +2,43 @@ package style
 
 type FileTypeIcon struct {
 	Icon  string
-	Color string
+	Color string // hex, a theme key such as "icon-go", or empty for the theme's directory or file color
+}
+
+// WithColor returns the icon with color filled in if it has no color of
+// its own, and with a color given as a theme key replaced by that color in
+// the current theme.
+func (i FileTypeIcon) WithColor(color string) FileTypeIcon {
+	if i.Color == "" {
+		i.Color = color
+	} else if themed, ok := Current.colors()[i.Color]; ok {
+		i.Color = *themed
+	}
+	return i
 }
 
 // Maps to hold icon and color definitions for different file types
 var FileTypeIconMap = map[string]FileTypeIcon{
-	"Music":     {" ", "#00FFFF"},
-	"Documents": {" ", "#00FFFF"},
-	"Downloads": {"󱑢 ", "#00FFFF"},
-	"Pictures":  {" ", "#00FFFF"},
-	"Videos":    {"󰕧 ", "#00FFFF"},
-	"Desktop":   {" ", "#00FFFF"},
-	"Public":    {" ", "#00FFFF"},
+	"Music":     {" ", ""},
+	"Documents": {" ", ""},
+	"Downloads": {"󱑢 ", ""},
+	"Pictures":  {" ", ""},
+	"Videos":    {"󰕧 ", ""},
+	"Desktop":   {" ", ""},
+	"Public":    {" ", ""},
 }
 
 var ExtToFileTypeIconMap = map[string]FileTypeIcon{
-	".pub":  {"󱕵 ", "#FFFF00"},
-	".py":   {"󰌠 ", "#add8e6"},
-	".go":   {" ", "#039eff"},
-	".zip":  {" ", "#FF0000"},
-	".pdf":  {" ", "#FF0000"},
-	".png":  {" ", "#00FF00"},
-	".rs":   {"󰇷 ", "#663300"},
-	".json": {"󰘦 ", "#FFFFFF"},
-	".dmg":  {"󱧘 ", "#9900ff"},
-	".txt":  {" ", "#FFFFFF"},
-	".list": {" ", "#FFFFFF"},
+	".pub":    {"󱕵 ", "icon-key"},
+	".py":     {"󰌠 ", "icon-python"},
+	".go":     {" ", "icon-go"},
+	".zip":    {" ", "icon-archive"},
+	".tar.gz": {" ", "icon-archive"},
+	".pdf":    {" ", "icon-document"},
+	".png":    {" ", "icon-image"},
+	".rs":     {"󰇷 ", "icon-rust"},
+	".json":   {"󰘦 ", ""},
+	".dmg":    {"󱧘 ", "icon-disk-image"},
+	".txt":    {" ", ""},
+	".list":   {" ", ""},
 }
@@ -0,0 +1,265 @@
+package style
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds every color the renderers use, as hex strings.
+type Theme struct {
+	Directory  string
+	File       string
+	Symlink    string
+	Executable string
+	Read       string
+	Write      string
+	Execute    string
+	SetID      string
+	Sticky     string
+	SizeSmall  string
+	SizeLarge  string
+	DateToday  string
+	DateOlder  string
+	User       string
+	Group      string
+	Inode      string
+	Header     string
+	Background string // only used by exports such as HTML
+
+	// Icon colors, referred to by the built-in icons by their theme key.
+	IconArchive   string
+	IconDocument  string
+	IconImage     string
+	IconDiskImage string
+	IconKey       string
+	IconEnv       string
+	IconGo        string
+	IconPython    string
+	IconRust      string
+	IconBuild     string
+	IconDocker    string
+	IconGit       string
+	IconLicense   string
+}
+
+// Dark is the default theme, meant for terminals with a dark background.
+var Dark = Theme{
+	Directory:  "#00FFFF",
+	File:       "#FFFFFF",
+	Symlink:    "#BE67F5",
+	Executable: "#ff0303",
+	Read:       "#00FF00",
+	Write:      "#FFA500",
+	Execute:    "#FF0000",
+	SetID:      "#FFD700",
+	Sticky:     "#FFC0CB",
+	SizeSmall:  "#FFFFFF",
+	SizeLarge:  "#FFA500",
+	DateToday:  "#02e05f",
+	DateOlder:  "#02bd91",
+	User:       "#fcfbd2",
+	Group:      "#d1d0ab",
+	Inode:      "#FFFFFF",
+	Header:     "#FFFFFF",
+	Background: "#1e1e1e",
+
+	IconArchive:   "#FF0000",
+	IconDocument:  "#FF0000",
+	IconImage:     "#00FF00",
+	IconDiskImage: "#9900ff",
+	IconKey:       "#FFFF00",
+	IconEnv:       "#faf743",
+	IconGo:        "#039eff",
+	IconPython:    "#add8e6",
+	IconRust:      "#663300",
+	IconBuild:     "#6d8086",
+	IconDocker:    "#458ee6",
+	IconGit:       "#f54d27",
+	IconLicense:   "#d0bf41",
+}
+
+// Light is meant for terminals with a light background.
+var Light = Theme{
+	Directory:  "#005f87",
+	File:       "#1c1c1c",
+	Symlink:    "#8700af",
+	Executable: "#d70000",
+	Read:       "#008700",
+	Write:      "#af5f00",
+	Execute:    "#d70000",
+	SetID:      "#af8700",
+	Sticky:     "#d7005f",
+	SizeSmall:  "#1c1c1c",
+	SizeLarge:  "#af5f00",
+	DateToday:  "#008700",
+	DateOlder:  "#005f5f",
+	User:       "#5f5f00",
+	Group:      "#875f00",
+	Inode:      "#1c1c1c",
+	Header:     "#1c1c1c",
+	Background: "#ffffff",
+
+	IconArchive:   "#d70000",
+	IconDocument:  "#d70000",
+	IconImage:     "#008700",
+	IconDiskImage: "#5f00af",
+	IconKey:       "#af8700",
+	IconEnv:       "#af8700",
+	IconGo:        "#0087af",
+	IconPython:    "#005f87",
+	IconRust:      "#875f00",
+	IconBuild:     "#585858",
+	IconDocker:    "#005faf",
+	IconGit:       "#d75f00",
+	IconLicense:   "#878700",
+}
+
+// HighContrast uses only fully saturated colors on black.
+var HighContrast = Theme{
+	Directory:  "#00FFFF",
+	File:       "#FFFFFF",
+	Symlink:    "#FF00FF",
+	Executable: "#FF0000",
+	Read:       "#00FF00",
+	Write:      "#FFFF00",
+	Execute:    "#FF0000",
+	SetID:      "#FFFF00",
+	Sticky:     "#FF00FF",
+	SizeSmall:  "#FFFFFF",
+	SizeLarge:  "#FFFF00",
+	DateToday:  "#00FF00",
+	DateOlder:  "#FFFFFF",
+	User:       "#FFFFFF",
+	Group:      "#FFFFFF",
+	Inode:      "#FFFFFF",
+	Header:     "#FFFFFF",
+	Background: "#000000",
+
+	IconArchive:   "#FF0000",
+	IconDocument:  "#FF0000",
+	IconImage:     "#00FF00",
+	IconDiskImage: "#FF00FF",
+	IconKey:       "#FFFF00",
+	IconEnv:       "#FFFF00",
+	IconGo:        "#00FFFF",
+	IconPython:    "#00FFFF",
+	IconRust:      "#FFFF00",
+	IconBuild:     "#FFFFFF",
+	IconDocker:    "#00FFFF",
+	IconGit:       "#FF0000",
+	IconLicense:   "#FFFF00",
+}
+
+// Current is the theme all renderers draw their colors from.
+var Current = Dark
+
+var builtinThemes = map[string]Theme{
+	"dark":          Dark,
+	"light":         Light,
+	"high-contrast": HighContrast,
+}
+
+// LoadTheme resolves the --theme value name: "auto" picks dark or light
+// from the terminal background, the name of a built-in theme selects it,
+// and anything else is read as a theme file.
+//
+// A theme file has one "key = #rrggbb" line per color, using the keys
+// listed in Theme.colors. An optional "base = NAME" line starts from a
+// built-in theme instead of the dark one; colors that are not set are
+// taken from the base.
+func LoadTheme(name string) (Theme, error) {
+	if name == "auto" {
+		if lipgloss.HasDarkBackground() {
+			return Dark, nil
+		}
+		return Light, nil
+	}
+	if theme, ok := builtinThemes[name]; ok {
+		return theme, nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return Theme{}, fmt.Errorf("unknown theme %q: %w", name, err)
+	}
+	defer f.Close()
+
+	base := Dark
+	colors := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return Theme{}, fmt.Errorf("%s:%d: expected key = value", name, lineNum)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		if key == "base" {
+			var ok bool
+			if base, ok = builtinThemes[value]; !ok {
+				return Theme{}, fmt.Errorf("%s:%d: unknown base theme %q", name, lineNum, value)
+			}
+			continue
+		}
+		if _, ok := base.colors()[key]; !ok {
+			return Theme{}, fmt.Errorf("%s:%d: unknown theme key %q", name, lineNum, key)
+		}
+		colors[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return Theme{}, err
+	}
+
+	theme := base
+	fields := theme.colors()
+	for key, value := range colors {
+		*fields[key] = value
+	}
+	return theme, nil
+}
+
+// colors maps theme file keys to the fields they set.
+func (t *Theme) colors() map[string]*string {
+	return map[string]*string{
+		"directory":       &t.Directory,
+		"file":            &t.File,
+		"symlink":         &t.Symlink,
+		"executable":      &t.Executable,
+		"read":            &t.Read,
+		"write":           &t.Write,
+		"execute":         &t.Execute,
+		"setid":           &t.SetID,
+		"sticky":          &t.Sticky,
+		"size-small":      &t.SizeSmall,
+		"size-large":      &t.SizeLarge,
+		"date-today":      &t.DateToday,
+		"date-older":      &t.DateOlder,
+		"user":            &t.User,
+		"group":           &t.Group,
+		"inode":           &t.Inode,
+		"header":          &t.Header,
+		"background":      &t.Background,
+		"icon-archive":    &t.IconArchive,
+		"icon-document":   &t.IconDocument,
+		"icon-image":      &t.IconImage,
+		"icon-disk-image": &t.IconDiskImage,
+		"icon-key":        &t.IconKey,
+		"icon-env":        &t.IconEnv,
+		"icon-go":         &t.IconGo,
+		"icon-python":     &t.IconPython,
+		"icon-rust":       &t.IconRust,
+		"icon-build":      &t.IconBuild,
+		"icon-docker":     &t.IconDocker,
+		"icon-git":        &t.IconGit,
+		"icon-license":    &t.IconLicense,
+	}
+}
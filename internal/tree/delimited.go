@@ -0,0 +1,51 @@
+package tree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/list"
+)
+
+// writeDelimitedTree lists every node below startPath as a CSV or TSV row.
+func writeDelimitedTree(startPath string, config config.Config) {
+	writer := list.NewDelimitedWriter(os.Stdout, config, true)
+	if err := writer.WriteHeader(); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing %s: %v\n", config.Format, err)
+		os.Exit(1)
+	}
+	delimitedDir(writer, startPath, 0, config.MaxDepth, config)
+	if err := writer.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing %s: %v\n", config.Format, err)
+		os.Exit(1)
+	}
+}
+
+func delimitedDir(writer *list.DelimitedWriter, path string, depth int, maxDepth int, config config.Config) {
+	if maxDepth != -1 && depth > maxDepth {
+		return
+	}
+
+	entries, err := readEntries(path, config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading directory:", path, "-", err)
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		if err := writer.Write(path, info); err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		if entry.IsDir() {
+			delimitedDir(writer, filepath.Join(path, entry.Name()), depth+1, maxDepth, config)
+		}
+	}
+}
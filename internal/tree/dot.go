@@ -0,0 +1,153 @@
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+)
+
+// dotWriter renders the tree as a Graphviz digraph. Directories become
+// clusters, everything else becomes a leaf node, and symlinks get a dashed
+// edge to their target.
+type dotWriter struct {
+	out     *bufio.Writer
+	config  config.Config
+	nextID  int
+	nodes   map[string]string // absolute path -> node id
+	dirs    map[string]int    // absolute path -> cluster number
+	links   []dotLink
+	indents int
+}
+
+type dotLink struct {
+	from   string
+	target string // absolute path of the link target
+}
+
+// writeDotTree writes the tree rooted at startPath to stdout in DOT format.
+func writeDotTree(startPath string, config config.Config) {
+	d := &dotWriter{
+		out:    bufio.NewWriter(os.Stdout),
+		config: config,
+		nodes:  map[string]string{},
+		dirs:   map[string]int{},
+	}
+
+	baseDir := filepath.Base(startPath)
+	if startPath == "." || startPath == "./" {
+		baseDir = "."
+	}
+
+	d.line("digraph %s {", dotQuote(baseDir))
+	d.indents++
+	d.line("compound=true;")
+	d.line("rankdir=LR;")
+	d.line(`node [shape=box, fontname="monospace"];`)
+	d.writeDir(startPath, baseDir, 0)
+	d.writeLinks()
+	d.indents--
+	d.line("}")
+
+	if err := d.out.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing DOT: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writeDir writes the cluster for the directory at path. Each cluster has
+// an invisible anchor node so that empty directories are still drawn and
+// symlinks to directories have something to point at.
+func (d *dotWriter) writeDir(path, name string, depth int) {
+	cluster := len(d.dirs)
+	anchor := d.newID()
+	d.dirs[absPath(path)] = cluster
+	d.nodes[absPath(path)] = anchor
+
+	d.line("subgraph cluster_%d {", cluster)
+	d.indents++
+	d.line("label=%s;", dotQuote(name))
+	d.line(`%s [label="", shape=point, style=invis];`, anchor)
+
+	maxDepth := d.config.MaxDepth
+	if maxDepth == -1 || depth <= maxDepth {
+		entries, err := readEntries(path, d.config)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error reading directory:", path, "-", err)
+		}
+		for _, entry := range entries {
+			entryPath := filepath.Join(path, entry.Name())
+			if entry.IsDir() {
+				d.writeDir(entryPath, entry.Name(), depth+1)
+				continue
+			}
+			id := d.newID()
+			d.nodes[absPath(entryPath)] = id
+			d.line("%s [label=%s];", id, dotQuote(entry.Name()))
+			if entry.Type()&os.ModeSymlink != 0 {
+				d.addLink(id, path, entryPath)
+			}
+		}
+	}
+
+	d.indents--
+	d.line("}")
+}
+
+func (d *dotWriter) addLink(id, dir, linkPath string) {
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading symlink target: %v\n", err)
+		return
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	d.links = append(d.links, dotLink{from: id, target: absPath(target)})
+}
+
+// writeLinks draws the symlink edges once every node is known. Targets
+// outside the drawn tree get a node of their own.
+func (d *dotWriter) writeLinks() {
+	for _, link := range d.links {
+		to, found := d.nodes[link.target]
+		if !found {
+			to = d.newID()
+			d.nodes[link.target] = to
+			d.line("%s [label=%s, style=dashed];", to, dotQuote(link.target))
+		}
+		if cluster, isDir := d.dirs[link.target]; isDir {
+			d.line("%s -> %s [style=dashed, lhead=cluster_%d];", link.from, to, cluster)
+		} else {
+			d.line("%s -> %s [style=dashed];", link.from, to)
+		}
+	}
+}
+
+func (d *dotWriter) newID() string {
+	d.nextID++
+	return fmt.Sprintf("n%d", d.nextID)
+}
+
+func (d *dotWriter) line(format string, a ...interface{}) {
+	d.out.WriteString(strings.Repeat("  ", d.indents))
+	fmt.Fprintf(d.out, format, a...)
+	d.out.WriteString("\n")
+}
+
+// dotQuote returns s as a quoted DOT string.
+func dotQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
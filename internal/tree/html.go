@@ -0,0 +1,133 @@
+package tree
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/list"
+	"github.com/SiirRandall/lsd-go/internal/style"
+)
+
+// htmlNode is a directory or file in the rendered page.
+type htmlNode struct {
+	Name      string
+	Icon      string
+	IconColor string
+	Color     string
+	Tooltip   string
+	IsDir     bool
+	Children  []htmlNode
+}
+
+type htmlPage struct {
+	Title      string
+	Background string
+	Foreground string
+	Generated  string
+	Root       htmlNode
+}
+
+// htmlTemplate renders the whole page. Directories use <details> so they can
+// be collapsed without any script, and everything is inlined so the page can
+// be archived as a single file.
+var htmlTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { background: {{.Background}}; color: {{.Foreground}}; font-family: "Symbols Nerd Font", "Hack Nerd Font", "FiraCode Nerd Font", monospace; }
+ul { list-style: none; margin: 0; padding-left: 1.5em; border-left: 1px dotted #555555; }
+summary { cursor: pointer; }
+footer { margin-top: 1em; color: #888888; font-size: small; }
+</style>
+</head>
+<body>
+{{template "node" .Root}}
+<footer>Generated by lsd-go on {{.Generated}}</footer>
+</body>
+</html>
+{{define "node"}}{{if .IsDir}}<details open><summary title="{{.Tooltip}}">{{template "label" .}}</summary>
+<ul>
+{{range .Children}}<li>{{template "node" .}}</li>
+{{end}}</ul>
+</details>{{else}}<span title="{{.Tooltip}}">{{template "label" .}}</span>{{end}}{{end}}
+{{define "label"}}<span style="color: {{.IconColor}}">{{.Icon}}</span><span style="color: {{.Color}}">{{.Name}}</span>{{end}}
+`))
+
+// writeHTMLTree writes a self-contained HTML page of the tree to stdout.
+func writeHTMLTree(startPath string, config config.Config) {
+	info, err := os.Stat(startPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	baseDir := filepath.Base(startPath)
+	if startPath == "." || startPath == "./" {
+		baseDir = "."
+	}
+	root := newHTMLNode(baseDir, info, style.DirIcon(baseDir))
+	root.Children = htmlDir(startPath, 0, config.MaxDepth, config)
+
+	page := htmlPage{
+		Title:      "lsd-go: " + startPath,
+		Background: style.Current.Background,
+		Foreground: style.Current.File,
+		Generated:  time.Now().Format("Mon Jan 02 15:04:05 2006"),
+		Root:       root,
+	}
+	if err := htmlTemplate.Execute(os.Stdout, page); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing HTML: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func htmlDir(path string, depth int, maxDepth int, config config.Config) []htmlNode {
+	if maxDepth != -1 && depth > maxDepth {
+		return nil
+	}
+
+	entries, err := readEntries(path, config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading directory:", path, "-", err)
+		return nil
+	}
+
+	var nodes []htmlNode
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		if entry.IsDir() {
+			node := newHTMLNode(entry.Name(), info, entryIcon(path, entry))
+			node.Children = htmlDir(filepath.Join(path, entry.Name()), depth+1, maxDepth, config)
+			nodes = append(nodes, node)
+		} else {
+			nodes = append(nodes, newHTMLNode(entry.Name(), info, entryIcon(path, entry)))
+		}
+	}
+	return nodes
+}
+
+func newHTMLNode(name string, info os.FileInfo, icon style.FileTypeIcon) htmlNode {
+	color := style.Current.File
+	if info.IsDir() {
+		color = style.Current.Directory
+	}
+	return htmlNode{
+		Name:      name,
+		Icon:      icon.Icon,
+		IconColor: icon.Color,
+		Color:     color,
+		Tooltip: fmt.Sprintf("Size: %s\nModified: %s",
+			list.HumanSize(info.Size()), info.ModTime().Format("Mon Jan 02 15:04:05 2006")),
+		IsDir: info.IsDir(),
+	}
+}
@@ -0,0 +1,54 @@
+package tree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/list"
+	"github.com/SiirRandall/lsd-go/internal/style"
+)
+
+// printMarkdownTree writes the tree as a nested Markdown bullet list.
+func printMarkdownTree(startPath string, config config.Config) {
+	baseDir := filepath.Base(startPath)
+	if startPath == "." || startPath == "./" {
+		baseDir = "."
+	}
+
+	var out strings.Builder
+	out.WriteString(markdownItem(0, baseDir, style.DirIcon(baseDir), config))
+	markdownDir(&out, startPath, 0, config.MaxDepth, config)
+	fmt.Print(out.String())
+}
+
+func markdownDir(out *strings.Builder, path string, depth int, maxDepth int, config config.Config) {
+	if maxDepth != -1 && depth > maxDepth {
+		return
+	}
+
+	entries, err := readEntries(path, config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading directory:", path, "-", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			out.WriteString(markdownItem(depth+1, entry.Name()+"/", entryIcon(path, entry), config))
+			markdownDir(out, filepath.Join(path, entry.Name()), depth+1, maxDepth, config)
+		} else {
+			out.WriteString(markdownItem(depth+1, entry.Name(), entryIcon(path, entry), config))
+		}
+	}
+}
+
+func markdownItem(level int, name string, icon style.FileTypeIcon, config config.Config) string {
+	item := list.MarkdownEscape(name)
+	if config.MarkdownIcons {
+		item = icon.Icon + item
+	}
+	return strings.Repeat("  ", level) + "- " + item + "\n"
+}
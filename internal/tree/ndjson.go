@@ -0,0 +1,88 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/osfiles"
+)
+
+// nodeRecord is a single line of the newline-delimited JSON tree output.
+// The root has depth 0 and an empty parent; its children have depth 1.
+type nodeRecord struct {
+	Path    string    `json:"path"`
+	Name    string    `json:"name"`
+	Depth   int       `json:"depth"`
+	Parent  string    `json:"parent"`
+	Type    string    `json:"type"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// streamTree writes one JSON object per node to stdout as the walk
+// progresses, so consumers can start reading before it finishes.
+func streamTree(startPath string, config config.Config) {
+	encoder := json.NewEncoder(os.Stdout)
+
+	info, err := os.Lstat(startPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading directory: %v\n", err)
+		os.Exit(1)
+	}
+	if !writeNode(encoder, startPath, "", 0, info) {
+		return
+	}
+	streamDir(encoder, startPath, 0, config.MaxDepth, config)
+}
+
+// streamDir mirrors traverseDir, including its depth limit and filtering.
+func streamDir(encoder *json.Encoder, path string, depth int, maxDepth int, config config.Config) bool {
+	if maxDepth != -1 && depth > maxDepth {
+		return true
+	}
+
+	entries, err := readEntries(path, config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading directory:", path, "-", err)
+		return true
+	}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(path, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+		if !writeNode(encoder, entryPath, path, depth+1, info) {
+			return false
+		}
+		if entry.IsDir() && !streamDir(encoder, entryPath, depth+1, maxDepth, config) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeNode encodes a single node. It reports false once stdout can no
+// longer be written to, e.g. because the reading end of a pipe went away.
+func writeNode(encoder *json.Encoder, path, parent string, depth int, info os.FileInfo) bool {
+	err := encoder.Encode(nodeRecord{
+		Path:    path,
+		Name:    info.Name(),
+		Depth:   depth,
+		Parent:  parent,
+		Type:    osfiles.FileType(info.Mode()),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing JSON: %v\n", err)
+		return false
+	}
+	return true
+}
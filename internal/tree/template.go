@@ -0,0 +1,71 @@
+package tree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/list"
+	"github.com/SiirRandall/lsd-go/internal/style"
+)
+
+// printTemplateTree draws the usual tree, rendering every node through
+// config.Template instead of the built-in icon and name.
+func printTemplateTree(startPath string, config config.Config) {
+	tmpl, err := list.ParseTemplate(config.Template, config.NoColor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+
+	info, err := os.Stat(startPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading directory: %v\n", err)
+		os.Exit(1)
+	}
+	root, err := tmpl.Render(filepath.Dir(startPath), info, style.DirIcon(filepath.Base(startPath)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(root)
+	templateDir(tmpl, startPath, 0, config.MaxDepth, config)
+}
+
+func templateDir(tmpl *list.Template, path string, depth int, maxDepth int, config config.Config) {
+	if maxDepth != -1 && depth > maxDepth {
+		return
+	}
+
+	entries, err := readEntries(path, config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading directory:", path, "-", err)
+		return
+	}
+
+	indent := strings.Repeat("│  ", depth)
+	prefix := "├── "
+	for i, entry := range entries {
+		if i == len(entries)-1 {
+			prefix = "└── "
+		}
+		info, err := entry.Info()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error retrieving file info: %v\n", err)
+			continue
+		}
+
+		out, err := tmpl.Render(path, info, entryIcon(path, entry))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(indent + prefix + out)
+
+		if entry.IsDir() {
+			templateDir(tmpl, filepath.Join(path, entry.Name()), depth+1, maxDepth, config)
+		}
+	}
+}
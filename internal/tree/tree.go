@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/osfiles"
 	"github.com/SiirRandall/lsd-go/internal/style"
 
 	"github.com/charmbracelet/lipgloss"
@@ -15,19 +15,44 @@ import (
 
 func Tree(config config.Config) {
 	startPath := config.Dir
+	if config.NullTerminate {
+		printNullTerminated(startPath, config)
+		return
+	}
+	if config.Template != "" {
+		printTemplateTree(startPath, config)
+		return
+	}
+	switch config.Format {
+	case "json":
+		streamTree(startPath, config)
+		return
+	case "csv", "tsv":
+		writeDelimitedTree(startPath, config)
+		return
+	case "html":
+		writeHTMLTree(startPath, config)
+		return
+	case "dot":
+		writeDotTree(startPath, config)
+		return
+	case "markdown":
+		printMarkdownTree(startPath, config)
+		return
+	}
 
 	// Get the base directory for output
 	baseDir := filepath.Base(startPath)
 	if startPath == "." || startPath == "./" {
 		baseDir = "."
 	}
-	iconStyle, found := style.FileTypeIconMap[baseDir]
-	if !found {
-		iconStyle = style.FileTypeIcon{Icon: " ", Color: "#00FFFF"}
-	}
+	iconStyle := style.DirIcon(baseDir)
 	icon := lipgloss.NewStyle().Foreground(lipgloss.Color(iconStyle.Color)).Render(iconStyle.Icon)
-	cyan := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF"))
+	cyan := lipgloss.NewStyle().Foreground(lipgloss.Color(style.Current.Directory))
 	coloredName := cyan.Render(baseDir)
+	if config.Hyperlinks {
+		coloredName = style.Hyperlink(coloredName, startPath)
+	}
 
 	result := icon + coloredName + "\n" + traverseDir(startPath, 0, config.MaxDepth, config)
 	fmt.Print(result)
@@ -38,31 +63,18 @@ func traverseDir(path string, depth int, maxDepth int, config config.Config) str
 		return ""
 	}
 
-	entries, err := os.ReadDir(path)
+	filteredEntries, err := readEntries(path, config)
 	if err != nil {
 		fmt.Println("Error reading directory:", path, "-", err)
 		return ""
 	}
 
-	var filteredEntries []os.DirEntry
-	for _, entry := range entries {
-		if !config.ShowDotFiles && strings.HasPrefix(entry.Name(), ".") {
-			continue
-		}
-		filteredEntries = append(filteredEntries, entry)
-	}
-
-	// Sort entries
-	sort.Slice(filteredEntries, func(i, j int) bool {
-		return strings.ToLower(filteredEntries[i].Name()) < strings.ToLower(filteredEntries[j].Name())
-	})
-
 	var out strings.Builder
 	indent := strings.Repeat("│  ", depth)
 	prefix := "├── "
 
-	cyan := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF"))
-	white := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+	cyan := lipgloss.NewStyle().Foreground(lipgloss.Color(style.Current.Directory))
+	white := lipgloss.NewStyle().Foreground(lipgloss.Color(style.Current.File))
 
 	for i, entry := range filteredEntries {
 		if i == len(filteredEntries)-1 {
@@ -70,22 +82,21 @@ func traverseDir(path string, depth int, maxDepth int, config config.Config) str
 		}
 
 		if entry.IsDir() {
-			iconStyle, found := style.FileTypeIconMap[entry.Name()]
-			if !found {
-				iconStyle = style.FileTypeIcon{Icon: " ", Color: "#00FFFF"}
-			}
+			iconStyle := entryIcon(path, entry)
 			icon := lipgloss.NewStyle().Foreground(lipgloss.Color(iconStyle.Color)).Render(iconStyle.Icon)
-			coloredName := cyan.Render(entry.Name())
+			coloredName := nameStyle(path, entry, cyan).Render(entry.Name() + indicator(entry, config))
+			if config.Hyperlinks {
+				coloredName = style.Hyperlink(coloredName, filepath.Join(path, entry.Name()))
+			}
 			out.WriteString(indent + prefix + icon + coloredName + "\n")
 			out.WriteString(traverseDir(filepath.Join(path, entry.Name()), depth+1, maxDepth, config))
 		} else {
-			ext := filepath.Ext(entry.Name())
-			iconStyle, found := style.ExtToFileTypeIconMap[ext]
-			if !found {
-				iconStyle = style.FileTypeIcon{Icon: " ", Color: "#FFFFFF"}
-			}
+			iconStyle := entryIcon(path, entry)
 			icon := lipgloss.NewStyle().Foreground(lipgloss.Color(iconStyle.Color)).Render(iconStyle.Icon)
-			coloredName := white.Render(entry.Name())
+			coloredName := nameStyle(path, entry, white).Render(entry.Name() + indicator(entry, config))
+			if config.Hyperlinks {
+				coloredName = style.Hyperlink(coloredName, filepath.Join(path, entry.Name()))
+			}
 			out.WriteString(indent + prefix + icon + coloredName + "\n")
 		}
 	}
@@ -93,6 +104,60 @@ func traverseDir(path string, depth int, maxDepth int, config config.Config) str
 	return out.String()
 }
 
+// nameStyle returns the style for the name of entry, which lives in dir:
+// the LS_COLORS style if enabled and set for it, and fallback otherwise,
+// dimmed if git ignores the entry and --git-ignore=dim is given.
+func nameStyle(dir string, entry os.DirEntry, fallback lipgloss.Style) lipgloss.Style {
+	path := filepath.Join(dir, entry.Name())
+	fallback = style.DimIgnored(fallback, path, entry.IsDir())
+	if style.NameColors == nil {
+		return fallback
+	}
+	info, err := entry.Info()
+	if err != nil {
+		return fallback
+	}
+	if entryStyle, ok := style.NameColors.Style(path, info); ok {
+		return style.DimIgnored(entryStyle, path, entry.IsDir())
+	}
+	return fallback
+}
+
+// indicator returns the type indicator config.IndicatorStyle asks for.
+func indicator(entry os.DirEntry, config config.Config) string {
+	if config.IndicatorStyle == "" {
+		return ""
+	}
+	info, err := entry.Info()
+	if err != nil {
+		return ""
+	}
+	return osfiles.Indicator(info.Mode(), config.IndicatorStyle)
+}
+
+// entryIcon returns the icon for entry, which lives in dir.
+func entryIcon(dir string, entry os.DirEntry) style.FileTypeIcon {
+	info, err := entry.Info()
+	if err != nil {
+		info = nil
+	}
+	return style.EntryIcon(filepath.Join(dir, entry.Name()), info)
+}
+
+// readEntries returns the entries of path that the tree should show, in the
+// order they should be shown.
+func readEntries(path string, config config.Config) ([]os.DirEntry, error) {
+	entries, err := osfiles.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	filteredEntries := osfiles.Filter(entries, path, config)
+
+	osfiles.Sort(filteredEntries, config.SortKeys, config.SortReverse, config.DirsFirst)
+	return filteredEntries, nil
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
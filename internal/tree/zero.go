@@ -0,0 +1,38 @@
+package tree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/osfiles"
+)
+
+// printNullTerminated writes the path of every node below startPath, each
+// followed by a NUL byte.
+func printNullTerminated(startPath string, config config.Config) {
+	osfiles.PrintNullTerminated(nullTerminatedDir(nil, startPath, 0, config.MaxDepth, config))
+}
+
+// nullTerminatedDir appends the paths of the nodes below path to paths.
+func nullTerminatedDir(paths []string, path string, depth int, maxDepth int, config config.Config) []string {
+	if maxDepth != -1 && depth > maxDepth {
+		return paths
+	}
+
+	entries, err := readEntries(path, config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading directory:", path, "-", err)
+		return paths
+	}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(path, entry.Name())
+		paths = append(paths, entryPath)
+		if entry.IsDir() {
+			paths = nullTerminatedDir(paths, entryPath, depth+1, maxDepth, config)
+		}
+	}
+	return paths
+}
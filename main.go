@@ -1,48 +1,308 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	flag "github.com/spf13/pflag"
+	"golang.org/x/term"
 
 	"github.com/SiirRandall/lsd-go/internal/config"
+	"github.com/SiirRandall/lsd-go/internal/filter"
 	"github.com/SiirRandall/lsd-go/internal/list"
+	"github.com/SiirRandall/lsd-go/internal/osfiles"
 	"github.com/SiirRandall/lsd-go/internal/stdls"
+	"github.com/SiirRandall/lsd-go/internal/style"
 	"github.com/SiirRandall/lsd-go/internal/tree"
 )
 
 var (
+	showAll          = flag.BoolP("all", "a", false, "Show hidden files, and the . and .. entries")
+	showAlmostAll    = flag.BoolP("almost-all", "A", false, "Show hidden files, but not the . and .. entries")
 	showDotFiles     = flag.Bool("a", false, "Show dotfiles")
+	color            = flag.String("color", "auto", "Color output: auto, always or never")
 	noColor          = flag.Bool("no-color", false, "Disable colored output")
+	ignoreGlobs      = flag.StringArrayP("ignore-glob", "I", nil, "Hide entries matching any of these |-separated patterns (glob, ** or re:regexp); repeatable")
+	matchGlobs       = flag.StringArray("match", nil, "Only list entries matching any of these |-separated patterns (glob, ** or re:regexp); repeatable")
+	gitIgnore        = flag.String("git-ignore", "off", "Hide (--git-ignore) or dim (--git-ignore=dim) entries ignored by git; off to list them as usual")
+	types            = flag.String("type", "", "Only list entries of these comma-separated types: f (file), d (directory), l (symlink), p (pipe), s (socket), b (block device), c (character device)")
+	onlyDirs         = flag.Bool("only-dirs", false, "Only list directories, like --type=d")
+	onlyFiles        = flag.Bool("only-files", false, "Only list regular files, like --type=f")
+	where            = flag.String("where", "", "Only list entries matching an expression, e.g. 'size > 10MB && mtime < 7d && !hidden'")
 	showInodes       = flag.Bool("inodes", false, "Show inodes")
 	headers          = flag.Bool("headers", false, "Show headers")
 	listDetails      = flag.BoolP("list", "l", false, "List")
 	sortAlphabetical = flag.Bool("alpha", false, "Sort files alphabetically")
+	sortBy           = flag.String("sort", "name", "Sort by a comma-separated list of keys: name, size, time, atime, ctime, extension, natural (or version), inode or none")
+	collation        = flag.String("collate", "locale", "Order names by: bytes, unicode or locale (from LC_COLLATE/LANG)")
 	sortReverse      = flag.Bool("reverse", false, "Sort files in reverse order")
-	dirsFirst        = flag.Bool("dirsfirst", false, "Sort directories first and then files alphabetically")
+	dirsFirst        = flag.Bool("dirsfirst", false, "List directories before other entries, whatever the sort order")
 	maxDepth         = flag.Int("depth", 1, "Maximum depth for directory traversal. -1 means no limit.")
 	treeview         = flag.Bool("tree", false, "Show tree view")
+	jsonOutput       = flag.Bool("json", false, "Output the long listing (-l) as JSON, or the tree view (--tree) as newline-delimited JSON")
+	templateText     = flag.String("template", "", "Render each entry through a Go text/template")
+	templateFile     = flag.String("template-file", "", "Read the --template from a file")
+	onePerLine       = flag.BoolP("one-per-line", "1", false, "List one entry per line instead of a grid")
+	nullTerminate    = flag.Bool("zero", false, "End each name with a NUL byte instead of a newline, without styling")
+	hyperlink        = flag.String("hyperlink", "never", "Link file names to their paths with OSC 8: auto, always or never")
+	classify         = flag.BoolP("classify", "F", false, "Append an indicator (one of /*@|=) to entries, like --indicator-style=classify")
+	indicatorStyle   = flag.String("indicator-style", "none", "Append indicators to entry names: none, slash, file-type or classify")
+	markdownIcons    = flag.Bool("markdown-icons", false, "Include icons in markdown output")
+	icon             = flag.String("icon", "auto", "Show icons: auto, always or never")
+	iconTheme        = flag.String("icon-theme", "nerd", "Icon glyphs: nerd (needs a Nerd Font), unicode or ascii")
+	sniffContent     = flag.Bool("sniff", false, "Choose icons and colors from file contents (binaries, scripts, images, archives), not only names")
+	showKind         = flag.Bool("kind", false, "Show a Kind column describing each file's content in the long listing")
+	lsColors         = flag.Bool("ls-colors", true, "Color file names from the LS_COLORS environment variable when it is set (--ls-colors=false uses the theme)")
+	theme            = flag.String("theme", "auto", "Color theme: auto, dark, light, high-contrast or the path of a theme file")
+	configPath       = flag.String("config", config.FilePath(), "Path of the configuration file")
+	iconsPath        = flag.String("icons-file", "", "Path of a file with additional icon mappings (default: icons next to the configuration file)")
+	profile          = flag.String("profile", "", "Apply the named profile from the configuration file")
+	format           = flag.String("format", "", "Output format: json, csv, tsv or markdown for the long listing and tree view, html or dot for the tree view")
 )
 
 func main() {
+	flag.CommandLine.MarkDeprecated("no-color", "use --color=never instead")
+	flag.CommandLine.MarkDeprecated("a", "use -A/--almost-all, or -a/--all to include . and ..")
+	flag.Lookup("git-ignore").NoOptDefVal = "hide"
 	flag.Parse()
+	if err := applyConfigFile(); err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *noColor {
+		*color = "never"
+	}
+	colorEnabled, err := style.SetColorMode(*color)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+
+	currentTheme, err := style.LoadTheme(*theme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading theme: %v\n", err)
+		os.Exit(2)
+	}
+	style.Current = currentTheme
+	if *iconsPath == "" {
+		*iconsPath = style.IconsFilePath(*configPath)
+	}
+	if err := style.LoadIcons(*iconsPath); err != nil {
+		fmt.Fprintf(os.Stderr, "error loading icons: %v\n", err)
+		os.Exit(2)
+	}
+	if !style.ValidIconTheme(*iconTheme) {
+		fmt.Fprintf(os.Stderr, "invalid value %q for --icon-theme: expected nerd, unicode or ascii\n", *iconTheme)
+		os.Exit(2)
+	}
+	if value := os.Getenv("LS_COLORS"); *lsColors && value != "" {
+		style.NameColors = style.ParseLSColors(value)
+	}
+	style.IconTheme = *iconTheme
+	style.SniffContent = *sniffContent
 
 	dir := "."
 	if flag.NArg() > 0 {
 		dir = flag.Arg(flag.NArg() - 1)
 	}
+	outputFormat := *format
+	if *jsonOutput {
+		outputFormat = "json"
+	}
+	switch outputFormat {
+	case "", "json", "csv", "tsv", "html", "dot", "markdown":
+	case "md":
+		outputFormat = "markdown"
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", outputFormat)
+		os.Exit(2)
+	}
+	if (outputFormat == "html" || outputFormat == "dot") && !*treeview {
+		fmt.Fprintf(os.Stderr, "format %q is only available with --tree\n", outputFormat)
+		os.Exit(2)
+	}
+	// "auto" is about whether a terminal will show the icons; exports that
+	// ask for icons get them wherever they are written to.
+	if *icon == "auto" && (outputFormat == "html" || outputFormat == "markdown" && *markdownIcons) {
+		style.ShowIcons = true
+	} else {
+		style.ShowIcons = whenEnabled("icon", *icon)
+	}
+
+	entryTemplate := *templateText
+	if *templateFile != "" {
+		if entryTemplate != "" {
+			fmt.Fprintln(os.Stderr, "--template and --template-file cannot be combined")
+			os.Exit(2)
+		}
+		content, err := os.ReadFile(*templateFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading template: %v\n", err)
+			os.Exit(2)
+		}
+		entryTemplate = string(content)
+	}
+	if *nullTerminate && (entryTemplate != "" || outputFormat != "") {
+		fmt.Fprintln(os.Stderr, "--zero cannot be combined with --template, --format or --json")
+		os.Exit(2)
+	}
+	if entryTemplate != "" && outputFormat != "" {
+		fmt.Fprintln(os.Stderr, "--template cannot be combined with --format or --json")
+		os.Exit(2)
+	}
+
+	indicators := *indicatorStyle
+	if *classify {
+		indicators = "classify"
+	}
+	switch indicators {
+	case "none":
+		indicators = ""
+	case "slash", "file-type", "classify":
+	default:
+		fmt.Fprintf(os.Stderr, "invalid value %q for --indicator-style: expected none, slash, file-type or classify\n", indicators)
+		os.Exit(2)
+	}
+
+	sortKeys, err := osfiles.ParseSortKeys(*sortBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid value %q for --sort: %v\n", *sortBy, err)
+		os.Exit(2)
+	}
+
+	if err := osfiles.SetCollation(*collation); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid value %q for --collate: %v\n", *collation, err)
+		os.Exit(2)
+	}
+
+	ignore, err := parsePatterns(*ignoreGlobs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--ignore-glob: %v\n", err)
+		os.Exit(2)
+	}
+	match, err := parsePatterns(*matchGlobs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--match: %v\n", err)
+		os.Exit(2)
+	}
+
+	var gitIgnored *filter.GitIgnore
+	switch *gitIgnore {
+	case "off":
+	case "hide":
+		gitIgnored = filter.NewGitIgnore(dir)
+	case "dim":
+		if ignore := filter.NewGitIgnore(dir); ignore != nil {
+			style.GitIgnored = ignore.Ignored
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "invalid value %q for --git-ignore: expected hide, dim or off\n", *gitIgnore)
+		os.Exit(2)
+	}
+
+	entryTypes := strings.ReplaceAll(*types, ",", "")
+	if strings.Trim(entryTypes, filter.TypeLetters) != "" {
+		fmt.Fprintf(os.Stderr, "invalid value %q for --type: expected a comma-separated list of %s\n", *types, strings.Join(strings.Split(filter.TypeLetters, ""), ", "))
+		os.Exit(2)
+	}
+	if *onlyDirs && *onlyFiles {
+		fmt.Fprintln(os.Stderr, "--only-dirs and --only-files cannot be combined")
+		os.Exit(2)
+	}
+	if *onlyDirs {
+		entryTypes += "d"
+	}
+	if *onlyFiles {
+		entryTypes += "f"
+	}
+
+	var condition *filter.Expr
+	if *where != "" {
+		condition, err = filter.ParseExpr(*where)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --where expression: %v\n", err)
+			if syntaxErr, ok := err.(*filter.SyntaxError); ok {
+				fmt.Fprintf(os.Stderr, "  %s\n  %*s\n", *where, syntaxErr.Column, "^")
+			}
+			os.Exit(2)
+		}
+	}
+
 	config := config.Config{
 		SortAlphabetical: *sortAlphabetical,
+		SortKeys:         sortKeys,
 		SortReverse:      *sortReverse,
 		DirsFirst:        *dirsFirst,
-		ShowDotFiles:     *showDotFiles,
+		ShowDotFiles:     *showAll || *showAlmostAll || *showDotFiles,
+		ShowDotEntries:   *showAll,
+		Ignore:           ignore,
+		Match:            match,
+		GitIgnore:        gitIgnored,
+		Types:            entryTypes,
+		Where:            condition,
+		Listed:           map[string]bool{},
+		ShowInodes:       *showInodes,
+		Headers:          *headers,
+		NoColor:          !colorEnabled,
+		Format:           outputFormat,
+		Template:         entryTemplate,
+		MarkdownIcons:    *markdownIcons,
+		OnePerLine:       *onePerLine,
+		NullTerminate:    *nullTerminate,
+		Hyperlinks:       whenEnabled("hyperlink", *hyperlink),
+		IndicatorStyle:   indicators,
+		ShowKind:         *showKind,
 		Dir:              dir,
+		Args:             flag.Args(), // Get the non-flag command-line arguments
+		Tree:             *treeview,
 		MaxDepth:         *maxDepth,
 	}
-	args := flag.Args() // Get the non-flag command-line arguments
-	if *listDetails {
-		list.ListFiles(args, *showDotFiles, *showInodes, *headers, *noColor)
+	if *listDetails || (outputFormat != "" && !*treeview) {
+		list.ListFiles(config)
 	} else if *treeview {
 		tree.Tree(config)
 	} else {
 		stdls.StdLS(config)
 	}
 }
+
+// applyConfigFile fills in the flags that were not given on the command
+// line from the configuration file and the selected profile.
+func applyConfigFile() error {
+	file, err := config.LoadFile(*configPath, flag.CommandLine.Changed("config"))
+	if err != nil {
+		return err
+	}
+	return config.Apply(flag.CommandLine, file, *profile)
+}
+
+// parsePatterns parses the values of a repeatable pattern flag.
+func parsePatterns(specs []string) ([]filter.Pattern, error) {
+	var patterns []filter.Pattern
+	for _, spec := range specs {
+		parsed, err := filter.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, parsed...)
+	}
+	return patterns, nil
+}
+
+// whenEnabled resolves the value of an auto|always|never flag, where auto
+// means only when stdout is a terminal.
+func whenEnabled(name, value string) bool {
+	switch value {
+	case "always":
+		return true
+	case "never":
+		return false
+	case "auto":
+		return term.IsTerminal(int(os.Stdout.Fd()))
+	}
+	fmt.Fprintf(os.Stderr, "invalid value %q for --%s: expected auto, always or never\n", value, name)
+	os.Exit(2)
+	return false
+}